@@ -123,7 +123,7 @@ func bindArray(field reflect.Value, formValues []string) error {
 
 	for i, value := range formValues {
 		if err = bindSingle(field.Index(i), value); err != nil {
-			return err
+			return &indexError{index: i, value: value, err: err}
 		}
 	}
 
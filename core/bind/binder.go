@@ -1,7 +1,9 @@
 package bind
 
 import (
+	"bytes"
 	"gin-core/core/color"
+	"io"
 	"net/http"
 	"net/url"
 )
@@ -10,34 +12,55 @@ type Binder interface {
 	Bind(req *http.Request, v any) error
 }
 
+// BodyBinder 从缓存的请求体中绑定, 同一个请求体可以被多次绑定
+type BodyBinder interface {
+	BindBody(body []byte, v any) error
+}
+
 type QueryBinder struct {
+	Strict *Strict
 }
 
 func (q QueryBinder) Bind(req *http.Request, v any) error {
 	var binder = URLValueBinder{
-		TagName:     formTag,
-		BindTagName: bindTag,
+		TagName:               formTag,
+		BindTagName:           bindTag,
+		Source:                SourceQuery,
+		DisallowUnknownFields: q.Strict.disallowUnknownFields(),
 	}
 
 	return binder.BindForm(req.URL.Query(), v)
 }
 
 type FormBinder struct {
+	Strict *Strict
 }
 
 func (f FormBinder) Bind(r *http.Request, v any) error {
-	var binder = URLValueBinder{TagName: formTag, BindTagName: bindTag}
+	var binder = URLValueBinder{
+		TagName:               formTag,
+		BindTagName:           bindTag,
+		Source:                SourceForm,
+		DisallowUnknownFields: f.Strict.disallowUnknownFields(),
+	}
 
-	return binder.BindForm(r.Form, v)
+	//r.Form 包含了地址栏参数, 只检测body中的未知参数
+	return binder.bindForm(r.Form, r.PostForm, v)
 }
 
 type MultipartFormBodyBinder struct {
+	Strict *Strict
 }
 
 func (m MultipartFormBodyBinder) Bind(r *http.Request, v any) error {
 	var binder = HttpMultipartFormBinder{
-		URLValueBinder: URLValueBinder{TagName: formTag, BindTagName: bindTag},
-		FieldTag:       fileTag,
+		URLValueBinder: URLValueBinder{
+			TagName:               formTag,
+			BindTagName:           bindTag,
+			Source:                SourceForm,
+			DisallowUnknownFields: m.Strict.disallowUnknownFields(),
+		},
+		FieldTag: fileTag,
 	}
 
 	return binder.BindMultipartForm(r.MultipartForm, v)
@@ -45,20 +68,45 @@ func (m MultipartFormBodyBinder) Bind(r *http.Request, v any) error {
 
 type JsonBodyBinder struct {
 	Serializer color.Serializer
+	Strict     *Strict
 }
 
 func (j JsonBodyBinder) Bind(r *http.Request, v any) error {
-	return j.Serializer.Decode(r.Body, v)
+	return j.decode(r.Body, v)
+}
+
+func (j JsonBodyBinder) BindBody(body []byte, v any) error {
+	return j.decode(bytes.NewReader(body), v)
 }
 
-type XmlBodyBinder struct {
+func (j JsonBodyBinder) decode(reader io.Reader, v any) error {
+	if j.Strict != nil {
+		if serializer, ok := j.Serializer.(color.StrictSerializer); ok {
+			return BodyError(SourceBody, serializer.DecodeStrict(reader, v, color.DecodeOptions{
+				DisallowUnknownFields: j.Strict.DisallowUnknownFields,
+				DisallowTrailingData:  j.Strict.DisallowTrailingData,
+			}))
+		}
+	}
+
+	return BodyError(SourceBody, j.Serializer.Decode(reader, v))
+}
+
+// SerializerBodyBinder 使用 Serializer 解析请求体, xml/yaml/toml/msgpack/cbor/protobuf 共用
+type SerializerBodyBinder struct {
 	Serializer color.Serializer
 }
 
-func (x XmlBodyBinder) Bind(r *http.Request, v any) error {
-	return x.Serializer.Decode(r.Body, v)
+func (s SerializerBodyBinder) Bind(r *http.Request, v any) error {
+	return BodyError(SourceBody, s.Serializer.Decode(r.Body, v))
 }
 
+func (s SerializerBodyBinder) BindBody(body []byte, v any) error {
+	return BodyError(SourceBody, s.Serializer.Decode(bytes.NewReader(body), v))
+}
+
+type XmlBodyBinder = SerializerBodyBinder
+
 type HeaderBinder struct {
 }
 
@@ -66,6 +114,7 @@ func (h HeaderBinder) Bind(r *http.Request, v any) error {
 	var binder = URLValueBinder{
 		TagName:     headerTag,
 		BindTagName: bindTag,
+		Source:      SourceHeader,
 	}
 
 	return binder.BindForm(url.Values(r.Header), v)
@@ -79,7 +128,7 @@ type URIBinder struct {
 }
 
 func (u URIBinder) Bind(r *http.Request, v any) error {
-	var binder = URLValueBinder{TagName: uriTag, BindTagName: bindTag}
+	var binder = URLValueBinder{TagName: uriTag, BindTagName: bindTag, Source: SourceURI}
 
 	return binder.BindForm(u.Values, v)
 }
@@ -0,0 +1,160 @@
+package bind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gin-core/core/color"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 参数来源
+const (
+	SourceQuery  = "query"
+	SourceForm   = "form"
+	SourceHeader = "header"
+	SourceURI    = "uri"
+	SourceBody   = "body"
+)
+
+// FieldError 单个字段绑定失败的详细信息
+type FieldError struct {
+	Source  string `json:"source"`  //参数来源 query/form/header/uri
+	Key     string `json:"key"`     //请求中的参数名
+	Field   string `json:"field"`   //结构体字段路径, 例: Tags[1]
+	Type    string `json:"type"`    //期望的类型
+	Value   string `json:"value"`   //原始值
+	Message string `json:"message"` //错误描述
+	Err     error  `json:"-"`       //原始错误
+}
+
+func (e *FieldError) Error() string {
+	if len(e.Field) == 0 {
+		return fmt.Sprintf("%s parameter `%s`: %s", e.Source, e.Key, e.Message)
+	}
+
+	return fmt.Sprintf("%s parameter `%s` bind to %s failed: %s", e.Source, e.Key, e.Field, e.Message)
+}
+
+// Unwrap 获取原始错误
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BindErrors 汇总所有字段的绑定错误
+type BindErrors []*FieldError
+
+func (e BindErrors) Error() string {
+	var messages = make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// IsBindError 判断是否是绑定参数错误
+func IsBindError(err error) bool {
+	var errs BindErrors
+	var fieldErr *FieldError
+
+	return errors.As(err, &errs) || errors.As(err, &fieldErr)
+}
+
+// indexError 数组/切片中某个元素绑定失败
+type indexError struct {
+	index int
+	value string
+	err   error
+}
+
+func (e *indexError) Error() string {
+	return fmt.Sprintf("index %d: %v", e.index, e.err)
+}
+
+func (e *indexError) Unwrap() error {
+	return e.err
+}
+
+// newFieldError 把底层的转换错误包装成 FieldError
+func newFieldError(source, key string, field reflect.StructField, values []string, err error) *FieldError {
+	var fieldErr = &FieldError{
+		Source: source,
+		Key:    key,
+		Field:  field.Name,
+		Type:   field.Type.String(),
+		Value:  strings.Join(values, ","),
+		Err:    err,
+	}
+
+	var idxErr *indexError
+	if errors.As(err, &idxErr) {
+		fieldErr.Field = fmt.Sprintf("%s[%d]", field.Name, idxErr.index)
+		fieldErr.Type = field.Type.Elem().String()
+		fieldErr.Value = idxErr.value
+		err = idxErr.err
+	}
+
+	var numErr *strconv.NumError
+	switch {
+	case errors.As(err, &numErr):
+		fieldErr.Message = fmt.Sprintf("expected %s, got %q (%v)", fieldErr.Type, fieldErr.Value, numErr.Err)
+	default:
+		fieldErr.Message = err.Error()
+	}
+
+	return fieldErr
+}
+
+// BodyError 把请求体的解析错误包装成 FieldError, 例: json 语法错误, 类型不匹配, 严格模式下的未知字段.
+// 请求体超出大小限制的错误原样返回, 此时需要返回413
+func BodyError(source string, err error) error {
+	//超出大小限制和调用方的使用错误不是请求参数的问题
+	var maxBytesErr *http.MaxBytesError
+	var invalidErr *json.InvalidUnmarshalError
+	if err == nil || errors.As(err, &maxBytesErr) || errors.As(err, &invalidErr) ||
+		errors.Is(err, ErrBodyTooLarge) || errors.Is(err, color.ErrNotProtoMessage) {
+		return err
+	}
+
+	var fieldErr *FieldError
+	var bindErrs BindErrors
+	if errors.As(err, &fieldErr) || errors.As(err, &bindErrs) {
+		return err
+	}
+
+	fieldErr = &FieldError{Source: source, Message: err.Error(), Err: err}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		fieldErr.Key = typeErr.Field
+		fieldErr.Field = typeErr.Field
+		fieldErr.Type = typeErr.Type.String()
+		fieldErr.Value = typeErr.Value
+		fieldErr.Message = fmt.Sprintf("expected %s, got %s", fieldErr.Type, typeErr.Value)
+	case errors.As(err, &syntaxErr):
+		fieldErr.Message = fmt.Sprintf("malformed json at offset %d: %s", syntaxErr.Offset, syntaxErr.Error())
+	case errors.Is(err, io.EOF):
+		fieldErr.Message = "request body is empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		fieldErr.Message = "request body is truncated"
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		//encoding/json 没有导出未知字段的错误类型
+		var key, err01 = strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		if err01 != nil {
+			key = strings.TrimPrefix(err.Error(), "json: unknown field ")
+		}
+
+		fieldErr.Key = key
+		fieldErr.Message = ErrUnknownField.Error()
+		fieldErr.Err = fmt.Errorf("%w: %v", ErrUnknownField, err)
+	}
+
+	return fieldErr
+}
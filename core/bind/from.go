@@ -6,7 +6,6 @@ import (
 	"mime/multipart"
 	"net/url"
 	"reflect"
-	"strings"
 )
 
 const (
@@ -33,69 +32,69 @@ type multipartFormBinder interface {
 type URLValueBinder struct {
 	TagName     string
 	BindTagName string
+	Source      string //参数来源, 用于错误提示
 	BindMethods map[string]BindMethod
+	//严格模式, 请求中存在无法绑定的参数时返回错误
+	DisallowUnknownFields bool
 }
 
-// BindForm 解析参数绑定到结构体上
+// BindForm 解析参数绑定到结构体上, 所有字段的转换错误会汇总到 BindErrors 中
 func (u URLValueBinder) BindForm(form url.Values, v any) error {
+	return u.bindForm(form, form, v)
+}
+
+// bindForm checked 为严格模式下需要检测未知参数的集合
+func (u URLValueBinder) bindForm(form, checked url.Values, v any) error {
 	var values = reflect.ValueOf(v)
 	if values.Kind() != reflect.Ptr {
 		return errors.New("pointer type required")
 	}
 
 	var value = values.Elem()
-	var t = reflect.TypeOf(v).Elem()
-	for i := 0; i < value.NumField(); i++ {
-		var field = t.Field(i)
-		//获取tag关联的值
-		var tag, ok = field.Tag.Lookup(u.TagName)
-		if !ok {
-			//默认标签值
-			tag = field.Name
-		}
+	var plan = loadPlan(value.Type(), u.TagName, u.BindTagName, "")
+	var errs BindErrors
+	if u.DisallowUnknownFields {
+		errs = plan.unknownKeys(u.Source, checked, nil)
+	}
 
-		var tags = strings.Split(tag, ",")
-		var formKey = tags[0]
-		var defFormValue []string
-		if len(tags) != 1 {
-			defFormValue = tags[1:]
-		}
+	for _, fp := range plan.fields {
+		var formValue, exist = form[fp.key]
+		if !exist && len(fp.defaults) > 0 {
+			if err := bind(value.Field(fp.index), fp.defaults); err != nil {
+				errs = append(errs, newFieldError(u.Source, fp.key, fp.field, fp.defaults, err))
+			}
 
-		//不进行解析的字段
-		if formKey == pass {
 			continue
 		}
 
-		var formValue, exist = form[formKey]
-		if !exist && len(defFormValue) > 0 {
-			if err := bind(value.Field(i), defFormValue); err != nil {
-				return err
+		if fp.hasBind {
+			var method = u.BindMethods[fp.bindTag]
+			if method == nil {
+				return errors.New("no method named " + fp.bindTag)
 			}
-		} else {
-			if customBindTag, ok01 := field.Tag.Lookup(u.BindTagName); ok01 {
-				if method := u.BindMethods[customBindTag]; method != nil {
-					if err := method(value.Field(i), formValue); err != nil {
-						return err
-					}
-
-					continue
-				}
 
-				return errors.New("no method named " + customBindTag)
+			if err := method(value.Field(fp.index), formValue); err != nil {
+				errs = append(errs, newFieldError(u.Source, fp.key, fp.field, formValue, err))
 			}
+
+			continue
 		}
 
-		if err := bind(value.Field(i), formValue); err != nil {
-			if len(defFormValue) > 0 { //尝试绑定默认值
-				if err = bind(value.Field(i), defFormValue); err != nil {
-					return err
+		if err := bind(value.Field(fp.index), formValue); err != nil {
+			if len(fp.defaults) > 0 { //尝试绑定默认值
+				if bind(value.Field(fp.index), fp.defaults) == nil {
+					continue
 				}
 			}
 
-			return err
+			errs = append(errs, newFieldError(u.Source, fp.key, fp.field, formValue, err))
 		}
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
+
 	return nil
 }
 
@@ -129,69 +128,53 @@ func (h *HttpMultipartFormBinder) BindMultipartForm(form *multipart.Form, v any)
 	}
 
 	value = value.Elem()
-	var t = reflect.TypeOf(v).Elem()
-	for i := 0; i < value.NumField(); i++ {
-		var field = t.Field(i)
-		var tag, ok = field.Tag.Lookup(h.TagName)
-		if !ok { //设置默认tag
-			tag = field.Name
-		}
-
-		var tags = strings.Split(tag, ",")
-		var formKey = tags[0]
-		var defFormValue []string
-
-		if len(tags) != 1 {
-			defFormValue = tags[1:]
-		}
-		if formKey == pass {
-			continue
-		}
+	var plan = loadPlan(value.Type(), h.TagName, h.BindTagName, h.FieldTag)
+	var errs BindErrors
+	if h.DisallowUnknownFields {
+		errs = plan.unknownKeys(h.Source, form.Value, form.File)
+	}
 
-		if formValue, exits := form.Value[formKey]; exits {
-			var customBindTag, ok01 = field.Tag.Lookup(h.BindTagName)
-			if ok01 {
-				if method := h.BindMethods[customBindTag]; method != nil {
-					if err := method(value.Field(i), formValue); err != nil {
-						return err
+	for _, fp := range plan.fields {
+		if formValue, exits := form.Value[fp.key]; exits {
+			if fp.hasBind {
+				if method := h.BindMethods[fp.bindTag]; method != nil {
+					if err := method(value.Field(fp.index), formValue); err != nil {
+						errs = append(errs, newFieldError(h.Source, fp.key, fp.field, formValue, err))
 					}
 				}
 
 				continue
 			}
 
-			if err := bind(value.Field(i), formValue); err != nil {
-				if len(defFormValue) > 0 {
-					if err = bind(value.Field(i), defFormValue); err != nil {
-						return err
-					}
+			if err := bind(value.Field(fp.index), formValue); err != nil {
+				if len(fp.defaults) > 0 && bind(value.Field(fp.index), fp.defaults) == nil {
+					continue
 				}
+
+				errs = append(errs, newFieldError(h.Source, fp.key, fp.field, formValue, err))
 			}
 
 			continue
-		} else if len(defFormValue) > 0 {
-			if err := bind(value.Field(i), defFormValue); err != nil {
-				return err
+		} else if len(fp.defaults) > 0 {
+			if err := bind(value.Field(fp.index), fp.defaults); err != nil {
+				errs = append(errs, newFieldError(h.Source, fp.key, fp.field, fp.defaults, err))
 			}
 		}
 
-		switch value.Field(i).Interface().(type) {
-		case *multipart.FileHeader, []*multipart.FileHeader:
-			var fileTagVal, ok01 = field.Tag.Lookup(h.FieldTag)
-			if ok01 {
-				fileTagVal = field.Name
-			}
-			if fileTagVal == pass {
-				break
-			}
+		if !fp.isFile {
+			continue
+		}
 
-			if files, ok02 := form.File[fileTagVal]; ok02 {
-				if err := bindFile(value.Field(i), files); err != nil {
-					return err
-				}
+		if files, ok := form.File[fp.fileKey]; ok {
+			if err := bindFile(value.Field(fp.index), files); err != nil {
+				errs = append(errs, newFieldError(h.Source, fp.fileKey, fp.field, nil, err))
 			}
 		}
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
+
 	return nil
 }
@@ -0,0 +1,129 @@
+package bind
+
+import (
+	"mime/multipart"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// fieldPlan 单个字段的绑定信息, 由结构体tag解析而来
+type fieldPlan struct {
+	index    int
+	field    reflect.StructField
+	key      string   //请求中的参数名
+	defaults []string //tag中设置的默认值
+	bindTag  string   //自定义绑定方法名
+	hasBind  bool
+	fileKey  string //文件参数名, 仅 multipart 使用
+	isFile   bool
+}
+
+// typePlan 结构体的绑定计划
+type typePlan struct {
+	fields []fieldPlan
+	keys   map[string]struct{} //所有能绑定的参数名, 用于检测未知参数
+}
+
+// planKey 相同的结构体在不同的tag下解析结果不同
+type planKey struct {
+	typ     reflect.Type
+	tagName string
+	bindTag string
+	fileTag string
+}
+
+// bindPlans 缓存每个结构体类型的解析结果, 并发安全
+var bindPlans sync.Map
+
+// loadPlan 获取结构体的绑定计划, 第一次使用时解析tag并缓存
+func loadPlan(t reflect.Type, tagName, bindTagName, fileTagName string) *typePlan {
+	var key = planKey{typ: t, tagName: tagName, bindTag: bindTagName, fileTag: fileTagName}
+	if plan, ok := bindPlans.Load(key); ok {
+		return plan.(*typePlan)
+	}
+
+	var plan = &typePlan{
+		fields: make([]fieldPlan, 0, t.NumField()),
+		keys:   make(map[string]struct{}, t.NumField()),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		var field = t.Field(i)
+		//获取tag关联的值
+		var tag, ok = field.Tag.Lookup(tagName)
+		if !ok {
+			//默认标签值
+			tag = field.Name
+		}
+
+		var tags = strings.Split(tag, ",")
+		//不进行解析的字段
+		if tags[0] == pass {
+			continue
+		}
+
+		var fp = fieldPlan{index: i, field: field, key: tags[0]}
+		if len(tags) != 1 {
+			fp.defaults = tags[1:]
+		}
+
+		fp.bindTag, fp.hasBind = field.Tag.Lookup(bindTagName)
+		if fileTagName != "" && (field.Type == fileHeaderType || field.Type == fileHeadersType) {
+			var fileTagVal, ok01 = field.Tag.Lookup(fileTagName)
+			if !ok01 {
+				fileTagVal = field.Name
+			}
+
+			fp.fileKey = fileTagVal
+			fp.isFile = fileTagVal != pass
+		}
+
+		plan.keys[fp.key] = struct{}{}
+		if fp.isFile {
+			plan.keys[fp.fileKey] = struct{}{}
+		}
+
+		plan.fields = append(plan.fields, fp)
+	}
+
+	var actual, _ = bindPlans.LoadOrStore(key, plan)
+
+	return actual.(*typePlan)
+}
+
+// unknownKeys 检测请求中结构体无法绑定的参数
+func (p *typePlan) unknownKeys(source string, values map[string][]string, files map[string][]*multipart.FileHeader) BindErrors {
+	var keys = make([]string, 0)
+	for key := range values {
+		if _, ok := p.keys[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	for key := range files {
+		if _, ok := p.keys[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	var errs BindErrors
+	for _, key := range keys {
+		errs = append(errs, &FieldError{
+			Source:  source,
+			Key:     key,
+			Value:   strings.Join(values[key], ","),
+			Message: ErrUnknownField.Error(),
+			Err:     ErrUnknownField,
+		})
+	}
+
+	return errs
+}
@@ -0,0 +1,21 @@
+package bind
+
+import "errors"
+
+var (
+	// ErrUnknownField 严格模式下请求中存在结构体没有的参数
+	ErrUnknownField = errors.New("unknown field")
+	// ErrBodyTooLarge 请求体超出大小限制
+	ErrBodyTooLarge = errors.New("request body too large")
+)
+
+// Strict 严格绑定模式, 为 nil 时不做任何限制
+type Strict struct {
+	DisallowUnknownFields bool  //拒绝结构体中不存在的参数 (json/form/query)
+	DisallowTrailingData  bool  //拒绝json值之后多余的数据
+	MaxBodySize           int64 //请求体最大字节数, 超出返回413, 0为不限制
+}
+
+func (s *Strict) disallowUnknownFields() bool {
+	return s != nil && s.DisallowUnknownFields
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"gin-core/core/bind"
 	"gin-core/core/color"
 	"gin-core/core/validators"
 	"mime"
@@ -19,18 +20,20 @@ type handleNode struct {
 
 // BluePrint 相当于工具箱, 各种组件绑定在他身上
 type BluePrint struct {
-	Name           string
-	Data           any         //当前BluePrint数据
-	fileStorage    FileStorage //文件存储器
-	parsers        Parsers
-	validator      validators.Validator
-	logger         Logger
-	xmlSerializer  color.Serializer
-	jsonSerializer color.Serializer
-	parent         *BluePrint
-	methodsTree    map[string][]*handleNode //各类请求方式对应的, 请求回调处理函数
-	middleware     []HandleFunc
-	prefix         string
+	Name        string
+	Data        any         //当前BluePrint数据
+	fileStorage FileStorage //文件存储器
+	parsers     Parsers
+	strict      *bind.Strict //严格绑定模式
+	templates   *HTMLTemplates
+	validator   validators.Validator
+	logger      Logger
+	serializers map[string]color.Serializer //媒体类型对应的序列化器
+	mediaTypes  []string                    //序列化器的注册顺序, 内容协商时越靠前优先级越高
+	parent      *BluePrint
+	methodsTree map[string][]*handleNode //各类请求方式对应的, 请求回调处理函数
+	middleware  []HandleFunc
+	prefix      string
 }
 
 // Use 添加中间件
@@ -200,44 +203,142 @@ func (b *BluePrint) IsRoot() bool {
 	return b.parent == nil
 }
 
-func (b BluePrint) XMLSerializer() color.Serializer {
-	if b.xmlSerializer != nil {
-		return b.xmlSerializer
+// Serializer 获取媒体类型对应的序列化器, 当前 BluePrint 没有设置时从父级查找
+func (b *BluePrint) Serializer(mediaType string) color.Serializer {
+	if serializer, ok := b.serializers[mediaType]; ok {
+		return serializer
 	}
 
 	if !b.IsRoot() {
-		return b.Parent().XMLSerializer()
+		return b.Parent().Serializer(mediaType)
 	}
 
 	return nil
 }
 
+// SetSerializer 设置媒体类型对应的序列化器
+func (b *BluePrint) SetSerializer(mediaType string, serializer color.Serializer) {
+	if serializer == nil {
+		panic("serializer can not be nil")
+	}
+
+	if b.serializers == nil {
+		b.serializers = make(map[string]color.Serializer, 0)
+	}
+
+	if _, ok := b.serializers[mediaType]; !ok {
+		b.mediaTypes = append(b.mediaTypes, mediaType)
+	}
+
+	b.serializers[mediaType] = serializer
+}
+
+// MediaTypes 所有已注册序列化器的媒体类型, 父级注册的排在前面
+func (b *BluePrint) MediaTypes() []string {
+	var mediaTypes []string
+	if !b.IsRoot() {
+		mediaTypes = b.Parent().MediaTypes()
+	}
+
+	for _, mediaType := range b.mediaTypes {
+		var exist = false
+		for _, item := range mediaTypes {
+			if item == mediaType {
+				exist = true
+				break
+			}
+		}
+
+		if !exist {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+
+	return mediaTypes
+}
+
+func (b *BluePrint) XMLSerializer() color.Serializer {
+	return b.Serializer(mimeXml)
+}
+
 func (b *BluePrint) SetXMLSerializer(xml color.Serializer) {
 	if xml == nil {
 		panic("xmlSerializer can not be nil")
 	}
 
-	b.xmlSerializer = xml
+	b.SetSerializer(mimeXml, xml)
 }
 
 func (b *BluePrint) JSONSerializer() color.Serializer {
-	if b.jsonSerializer != nil {
-		return b.jsonSerializer
+	return b.Serializer(mimeJson)
+}
+
+func (b *BluePrint) SetJSONSerializer(json color.Serializer) {
+	if json == nil {
+		panic("jsonSerializer can not be nil")
 	}
 
-	if !b.IsRoot() {
-		return b.Parent().JSONSerializer()
+	b.SetSerializer(mimeJson, json)
+}
+
+func (b *BluePrint) YAMLSerializer() color.Serializer {
+	return b.Serializer(mimeYaml)
+}
+
+func (b *BluePrint) SetYAMLSerializer(yaml color.Serializer) {
+	if yaml == nil {
+		panic("yamlSerializer can not be nil")
 	}
 
-	return nil
+	b.SetSerializer(mimeYaml, yaml)
 }
 
-func (b *BluePrint) SetJSONSerializer(json color.Serializer) {
-	if json == nil {
-		panic("jsonSerializer can not be nil")
+func (b *BluePrint) TOMLSerializer() color.Serializer {
+	return b.Serializer(mimeToml)
+}
+
+func (b *BluePrint) SetTOMLSerializer(toml color.Serializer) {
+	if toml == nil {
+		panic("tomlSerializer can not be nil")
+	}
+
+	b.SetSerializer(mimeToml, toml)
+}
+
+func (b *BluePrint) MsgPackSerializer() color.Serializer {
+	return b.Serializer(mimeMsgPack)
+}
+
+func (b *BluePrint) SetMsgPackSerializer(msgPack color.Serializer) {
+	if msgPack == nil {
+		panic("msgPackSerializer can not be nil")
+	}
+
+	b.SetSerializer(mimeMsgPack, msgPack)
+}
+
+func (b *BluePrint) CBORSerializer() color.Serializer {
+	return b.Serializer(mimeCbor)
+}
+
+func (b *BluePrint) SetCBORSerializer(cbor color.Serializer) {
+	if cbor == nil {
+		panic("cborSerializer can not be nil")
 	}
 
-	b.jsonSerializer = json
+	b.SetSerializer(mimeCbor, cbor)
+}
+
+func (b *BluePrint) ProtoBufSerializer() color.Serializer {
+	return b.Serializer(mimeProtoBuf)
+}
+
+func (b *BluePrint) SetProtoBufSerializer(protoBuf color.Serializer) {
+	if protoBuf == nil {
+		panic("protoBufSerializer can not be nil")
+	}
+
+	b.SetSerializer(mimeProtoBuf, protoBuf)
 }
 
 func (b *BluePrint) FileStorage() FileStorage {
@@ -292,6 +393,7 @@ func (b BluePrint) Validator() validators.Validator {
 	return nil
 }
 
+// SetValidator 设置验证器, 传入 validators.NewLibrary() 或 DefaultLibrary().Clone() 可以使用私有的规则
 func (b *BluePrint) SetValidator(validate validators.Validator) {
 	if validate == nil {
 		panic("validator can not be nil")
@@ -300,6 +402,46 @@ func (b *BluePrint) SetValidator(validate validators.Validator) {
 	b.validator = validate
 }
 
+func (b *BluePrint) Strict() *bind.Strict {
+	if b.strict != nil {
+		return b.strict
+	}
+
+	if !b.IsRoot() {
+		return b.Parent().Strict()
+	}
+
+	return nil
+}
+
+func (b *BluePrint) SetStrict(strict *bind.Strict) {
+	if strict == nil {
+		panic("strict can not be nil")
+	}
+
+	b.strict = strict
+}
+
+func (b *BluePrint) HTMLTemplates() *HTMLTemplates {
+	if b.templates != nil {
+		return b.templates
+	}
+
+	if !b.IsRoot() {
+		return b.Parent().HTMLTemplates()
+	}
+
+	return nil
+}
+
+func (b *BluePrint) SetHTMLTemplates(templates *HTMLTemplates) {
+	if templates == nil {
+		panic("templates can not be nil")
+	}
+
+	b.templates = templates
+}
+
 func (b *BluePrint) Logger() Logger {
 	if b.logger != nil {
 		return b.logger
@@ -364,12 +506,17 @@ func (b *BluePrint) Static(url, dir string, middleware ...HandleFunc) {
 }
 
 func (b *BluePrint) Default() *BluePrint {
-	b.SetFileStorage(&LocalFileStorage{})                                                 // 设置存储器
-	b.SetValidator(&validators.Default{})                                                 //设置验证器
-	b.SetParsers(Parsers{JsonParser{}, FormParser{}, MultipartFormParser{}, XMLParser{}}) //设置解析器
-	b.SetLogger(NewLogger())                                                              //设置日志处理器
-	b.SetJSONSerializer(color.JsonSerializer{})                                           //设置json解析器
-	b.SetXMLSerializer(color.XmlSerializer{})                                             //设置xml解析器
+	b.SetFileStorage(&LocalFileStorage{})               // 设置存储器
+	b.SetValidator(&validators.Default{})               //设置验证器
+	b.SetParsers(NewParsers())                          //设置解析器
+	b.SetLogger(NewLogger())                            //设置日志处理器
+	b.SetJSONSerializer(color.JsonSerializer{})         //设置json解析器
+	b.SetXMLSerializer(color.XmlSerializer{})           //设置xml解析器
+	b.SetYAMLSerializer(color.YamlSerializer{})         //设置yaml解析器
+	b.SetTOMLSerializer(color.TomlSerializer{})         //设置toml解析器
+	b.SetMsgPackSerializer(color.MsgPackSerializer{})   //设置msgpack解析器
+	b.SetCBORSerializer(color.CborSerializer{})         //设置cbor解析器
+	b.SetProtoBufSerializer(color.ProtoBufSerializer{}) //设置protobuf解析器
 
 	return b
 }
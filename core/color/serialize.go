@@ -3,14 +3,35 @@ package color
 import (
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"io"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
 )
 
+// ErrTrailingData 值之后存在多余的数据
+var ErrTrailingData = errors.New("unexpected data after top-level value")
+
 type Serializer interface {
 	Encode(writer io.Writer, v any) error
 	Decode(reader io.Reader, v any) error
 }
 
+// DecodeOptions 严格模式解析选项
+type DecodeOptions struct {
+	DisallowUnknownFields bool
+	DisallowTrailingData  bool
+}
+
+// StrictSerializer 支持严格模式的解析器, 未实现时严格模式退化为普通的 Decode
+type StrictSerializer interface {
+	DecodeStrict(reader io.Reader, v any, options DecodeOptions) error
+}
+
 type JsonSerializer struct {
 }
 
@@ -22,6 +43,26 @@ func (j JsonSerializer) Decode(reader io.Reader, v any) error {
 	return json.NewDecoder(reader).Decode(v)
 }
 
+func (j JsonSerializer) DecodeStrict(reader io.Reader, v any, options DecodeOptions) error {
+	var decoder = json.NewDecoder(reader)
+	if options.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if options.DisallowTrailingData {
+		//只剩空白字符时返回 io.EOF
+		if _, err := decoder.Token(); err != io.EOF {
+			return ErrTrailingData
+		}
+	}
+
+	return nil
+}
+
 type XmlSerializer struct {
 }
 
@@ -32,3 +73,90 @@ func (x XmlSerializer) Encode(writer io.Writer, v any) error {
 func (x XmlSerializer) Decode(reader io.Reader, v any) error {
 	return xml.NewDecoder(reader).Decode(v)
 }
+
+type YamlSerializer struct {
+}
+
+func (y YamlSerializer) Encode(writer io.Writer, v any) error {
+	var encoder = yaml.NewEncoder(writer)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+func (y YamlSerializer) Decode(reader io.Reader, v any) error {
+	return yaml.NewDecoder(reader).Decode(v)
+}
+
+type TomlSerializer struct {
+}
+
+func (t TomlSerializer) Encode(writer io.Writer, v any) error {
+	return toml.NewEncoder(writer).Encode(v)
+}
+
+func (t TomlSerializer) Decode(reader io.Reader, v any) error {
+	var _, err = toml.NewDecoder(reader).Decode(v)
+
+	return err
+}
+
+type MsgPackSerializer struct {
+}
+
+func (m MsgPackSerializer) Encode(writer io.Writer, v any) error {
+	return msgpack.NewEncoder(writer).Encode(v)
+}
+
+func (m MsgPackSerializer) Decode(reader io.Reader, v any) error {
+	return msgpack.NewDecoder(reader).Decode(v)
+}
+
+type CborSerializer struct {
+}
+
+func (c CborSerializer) Encode(writer io.Writer, v any) error {
+	return cbor.NewEncoder(writer).Encode(v)
+}
+
+func (c CborSerializer) Decode(reader io.Reader, v any) error {
+	return cbor.NewDecoder(reader).Decode(v)
+}
+
+// ErrNotProtoMessage protobuf 只能序列化 proto.Message
+var ErrNotProtoMessage = errors.New("proto.Message type required")
+
+// ProtoBufSerializer protobuf 二进制格式
+type ProtoBufSerializer struct {
+}
+
+func (p ProtoBufSerializer) Encode(writer io.Writer, v any) error {
+	var message, ok = v.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+
+	var data, err = proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(data)
+	return err
+}
+
+func (p ProtoBufSerializer) Decode(reader io.Reader, v any) error {
+	var message, ok = v.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+
+	var data, err = io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(data, message)
+}
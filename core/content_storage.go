@@ -0,0 +1,285 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var _ FileStorage = &ContentStorage{}
+
+// ContentStorage 按内容去重的文件存储器, 相同内容只保存一份, 以 SHA-256 命名,
+// 每次 Save 都返回新的逻辑名称, 引用计数为0时才删除内容.
+// URL 使用内容哈希, 内容不变地址就不变, 可以设置 Cache-Control: public, max-age=31536000, immutable
+type ContentStorage struct {
+	MediaRoot string
+	BaseURL   string         //访问文件的URL前缀, 例: /media/ 或 https://cdn.example.com/
+	Naming    NamingStrategy //逻辑名称的生成方式, 默认 SanitizedName
+	Index     RefIndex       //引用索引, 默认 MediaRoot/index.json
+	lock      sync.Mutex
+}
+
+const blobsDir = "blobs"
+
+func (c *ContentStorage) root() string {
+	return mediaRoot(c.MediaRoot)
+}
+
+// index 第一次使用时加载默认的索引文件
+func (c *ContentStorage) index() (RefIndex, error) {
+	if c.Index == nil {
+		var index, err = NewFileRefIndex(filepath.Join(c.root(), "index.json"))
+		if err != nil {
+			return nil, err
+		}
+
+		c.Index = index
+	}
+
+	return c.Index, nil
+}
+
+// blobPath 内容的存储路径, 例: blobs/ab/abcdef...
+func (c *ContentStorage) blobPath(hash string) string {
+	return filepath.Join(c.root(), blobsDir, hash[:2], hash)
+}
+
+// Save 保存上传的文件, 返回逻辑名称. 内容已存在时只增加引用
+func (c *ContentStorage) Save(fileHeader *multipart.FileHeader) (string, error) {
+	var name, err = storageName(c.Naming, fileHeader)
+	if err != nil {
+		return "", err
+	}
+
+	var digest = sha256.New()
+	var tmp string
+	var size int64
+	if tmp, size, err = writeTemp(fileHeader, filepath.Join(c.root(), blobsDir), digest); err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp)
+
+	var hash = hex.EncodeToString(digest.Sum(nil))
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var index RefIndex
+	if index, err = c.index(); err != nil {
+		return "", err
+	}
+
+	var blob = c.blobPath(hash)
+	var info, err01 = os.Stat(blob)
+	if os.IsNotExist(err01) {
+		if err = os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+			return "", err
+		}
+
+		if err = os.Rename(tmp, blob); err != nil {
+			return "", err
+		}
+
+		if info, err = os.Stat(blob); err != nil {
+			return "", err
+		}
+	} else if err01 != nil {
+		return "", err01
+	}
+
+	//不同的上传不能共用一个逻辑名称
+	var candidate = name
+	for attempt := 0; attempt < maxNameAttempts; attempt, candidate = attempt+1, alternativeName(name) {
+		var _, exists, err02 = index.Load(candidate)
+		if err02 != nil {
+			c.removeUnused(index, hash)
+			return "", err02
+		}
+
+		if exists {
+			continue
+		}
+
+		if err = index.Store(ContentRef{Name: candidate, Hash: hash, Size: size, ModTime: info.ModTime()}); err != nil {
+			c.removeUnused(index, hash)
+			return "", err
+		}
+
+		return candidate, nil
+	}
+
+	c.removeUnused(index, hash)
+	return "", fmt.Errorf("no available name for %q after %d attempts", name, maxNameAttempts)
+}
+
+// removeUnused 没有引用时删除内容
+func (c *ContentStorage) removeUnused(index RefIndex, hash string) {
+	if refs, err := index.Refs(hash); err == nil && refs == 0 {
+		_ = os.Remove(c.blobPath(hash))
+	}
+}
+
+// lookup 查找逻辑名称, 也可以是 URL 中使用的内容名称: 哈希+保存时的扩展名,
+// 扩展名必须和索引中的一致, 否则可以用 .html 之类的扩展名改变输出的 Content-Type
+func (c *ContentStorage) lookup(op, name string) (ContentRef, error) {
+	var index, err = c.index()
+	if err != nil {
+		return ContentRef{}, err
+	}
+
+	name = cleanName(name)
+	var ref, ok, err01 = index.Load(name)
+	if err01 != nil {
+		return ContentRef{}, err01
+	}
+
+	if ok {
+		return ref, nil
+	}
+
+	if hash := strings.TrimSuffix(name, path.Ext(name)); isContentHash(hash) {
+		if refs, err02 := index.ContentRefs(name); err02 == nil && refs > 0 {
+			var info, err03 = os.Stat(c.blobPath(hash))
+			if err03 == nil {
+				return ContentRef{Name: name, Hash: hash, Size: info.Size(), ModTime: info.ModTime()}, nil
+			}
+		}
+	}
+
+	return ContentRef{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// Open 打开已存储的文件
+func (c *ContentStorage) Open(name string) (io.ReadSeekCloser, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var ref, err = c.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(c.blobPath(ref.Hash))
+}
+
+// Delete 删除逻辑名称, 内容没有其他引用时一起删除
+func (c *ContentStorage) Delete(name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var index, err = c.index()
+	if err != nil {
+		return err
+	}
+
+	var ref, refs, err01 = index.Delete(cleanName(name))
+	if err01 != nil {
+		return err01
+	}
+
+	if refs == 0 {
+		return os.Remove(c.blobPath(ref.Hash))
+	}
+
+	return nil
+}
+
+// Stat 获取已存储文件的信息
+func (c *ContentStorage) Stat(name string) (FileInfo, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var ref, err = c.lookup("stat", name)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	return FileInfo{Name: ref.Name, Size: ref.Size, ModTime: ref.ModTime}, nil
+}
+
+// List 列出名称以 prefix 开头的所有逻辑名称, 按名称排序
+func (c *ContentStorage) List(prefix string) ([]FileInfo, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var index, err = c.index()
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []ContentRef
+	if refs, err = index.List(prefix); err != nil {
+		return nil, err
+	}
+
+	var files = make([]FileInfo, len(refs))
+	for i, ref := range refs {
+		files[i] = FileInfo{Name: ref.Name, Size: ref.Size, ModTime: ref.ModTime}
+	}
+
+	return files, nil
+}
+
+// Exists 文件是否存在
+func (c *ContentStorage) Exists(name string) (bool, error) {
+	var _, err = c.Stat(name)
+	if err == nil {
+		return true, nil
+	}
+
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// URL 使用内容哈希的访问地址, 例: /media/ab12...ef.png, 名称不存在时返回空字符串
+func (c *ContentStorage) URL(name string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var ref, err = c.lookup("url", name)
+	if err != nil {
+		return ""
+	}
+
+	return joinURL(c.BaseURL, url.PathEscape(ref.ContentName()))
+}
+
+// ContentHash 文件的内容哈希
+func (c *ContentStorage) ContentHash(name string) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var ref, err = c.lookup("stat", name)
+	if err != nil {
+		return "", err
+	}
+
+	return ref.Hash, nil
+}
+
+// isContentHash 64位十六进制小写字符串
+func isContentHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,13 +1,17 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"gin-core/core/bind"
+	"gin-core/core/validators"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 	"sync"
 	"time"
@@ -24,6 +28,9 @@ type Context struct {
 	abortIndex     uint8
 	status         uint //状态码
 	written        bool
+	bodyLimited    bool       //请求体是否已经限制大小
+	bodyCached     bool       //请求体是否已经读取
+	bodyCache      []byte     //原始请求体
 	queryCache     url.Values //地址栏参数
 	formCache      url.Values //body参数
 	items          map[string]any
@@ -43,6 +50,9 @@ func (c *Context) reset() {
 	c.formCache = nil
 	c.status = 0
 	c.written = false
+	c.bodyLimited = false
+	c.bodyCached = false
+	c.bodyCache = nil
 	c.abortIndex = 0
 }
 
@@ -112,13 +122,46 @@ func (c *Context) SaveUploadFileWith(fs FileStorage, name string) (string, error
 	return fs.Save(fileHeader)
 }
 
+// ServeStoredFile 输出 FileStorage 中的文件, 支持 Range 和 If-Modified-Since,
+// 文件不存在时返回的错误可以交给 AbortWithProblem 输出404
+func (c *Context) ServeStoredFile(name string) error {
+	return c.ServeStoredFileWith(c.BluePrint().FileStorage(), name)
+}
+
+func (c *Context) ServeStoredFileWith(fs FileStorage, name string) error {
+	if fs == nil {
+		return errors.New("`FileStorage` can be nil type")
+	}
+
+	var info, err = fs.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	var file io.ReadSeekCloser
+	if file, err = fs.Open(name); err != nil {
+		return err
+	}
+	defer file.Close()
+
+	//Content-Type 由扩展名决定, 禁止浏览器再猜测
+	c.ResponseWriter.Header().Set("X-Content-Type-Options", "nosniff")
+	return c.ServeContent(path.Base(info.Name), info.ModTime, file)
+}
+
 // Data 解析数据, 这里是重点代码块
 func (c *Context) Data(v any) error {
 	if err := c.BluePrint().Parsers().Parse(c, v); err != nil {
 		return err
 	}
 
-	return c.BluePrint().Validator().Validate(v)
+	var validator = c.BluePrint().Validator()
+	if ctxValidator, ok := validator.(validators.ContextValidator); ok {
+		var ctx = validators.WithLocale(c.Request.Context(), c.Locales()...)
+		return ctxValidator.ValidateContext(ctx, v)
+	}
+
+	return validator.Validate(v)
 }
 
 func (c *Context) Query() url.Values {
@@ -170,41 +213,155 @@ func (c *Context) ContentType() string {
 
 // Bind 实现绑定业务
 func (c *Context) Bind(binder bind.Binder, v any) error {
-	return binder.Bind(c.Request, v)
+	return c.bodyError(binder.Bind(c.Request, v))
+}
+
+// limitBody 按照 BluePrint 的严格模式限制请求体大小
+func (c *Context) limitBody() error {
+	var strict = c.BluePrint().Strict()
+	if strict == nil || strict.MaxBodySize <= 0 || c.bodyLimited {
+		return nil
+	}
+
+	c.bodyLimited = true
+	if c.Request.ContentLength > strict.MaxBodySize {
+		c.SetStatus(http.StatusRequestEntityTooLarge)
+		return bind.ErrBodyTooLarge
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.ResponseWriter, c.Request.Body, strict.MaxBodySize)
+	return nil
+}
+
+// bodyError 请求体超出限制时设置413状态码
+func (c *Context) bodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.SetStatus(http.StatusRequestEntityTooLarge)
+		return fmt.Errorf("%w: %v", bind.ErrBodyTooLarge, err)
+	}
+
+	return err
+}
+
+// maxBodySize 请求体最大字节数, 未开启严格模式时使用 MultipartMemory
+func (c *Context) maxBodySize() int64 {
+	if strict := c.BluePrint().Strict(); strict != nil && strict.MaxBodySize > 0 {
+		return strict.MaxBodySize
+	}
+
+	return c.Engine.MultipartMemory
+}
+
+// Body 读取并缓存原始请求体, 多次调用返回同一份数据,
+// 读取后 Request.Body 会被重置, 后续的解析器仍然可以正常读取
+func (c *Context) Body() ([]byte, error) {
+	if !c.bodyCached {
+		if err := c.limitBody(); err != nil {
+			return nil, err
+		}
+
+		var limit = c.maxBodySize()
+		var body, err = io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+		if err != nil {
+			return nil, c.bodyError(err)
+		}
+
+		if int64(len(body)) > limit {
+			c.SetStatus(http.StatusRequestEntityTooLarge)
+			return nil, bind.ErrBodyTooLarge
+		}
+
+		c.bodyCache = body
+		c.bodyCached = true
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(c.bodyCache))
+
+	return c.bodyCache, nil
+}
+
+// BindBody 从缓存的请求体中绑定
+func (c *Context) BindBody(binder bind.BodyBinder, v any) error {
+	var body, err = c.Body()
+	if err != nil {
+		return err
+	}
+
+	return binder.BindBody(body, v)
 }
 
 // BindQuery GET 查询参数bind
 func (c *Context) BindQuery(v any) error {
-	return c.Bind(bind.QueryBinder{}, v)
+	return c.Bind(bind.QueryBinder{Strict: c.BluePrint().Strict()}, v)
 }
 
 func (c *Context) BindForm(v any) error {
-	if err := c.Request.ParseForm(); err != nil {
+	if err := c.limitBody(); err != nil {
 		return err
 	}
 
-	return c.Bind(bind.FormBinder{}, v)
+	if err := c.Request.ParseForm(); err != nil {
+		return bind.BodyError(bind.SourceForm, c.bodyError(err))
+	}
+
+	return c.Bind(bind.FormBinder{Strict: c.BluePrint().Strict()}, v)
 }
 
 func (c *Context) BindMultipartForm(v any) error {
-	if err := c.Request.ParseMultipartForm(c.Engine.MultipartMemory); err != nil {
+	if err := c.limitBody(); err != nil {
 		return err
 	}
 
-	return c.Bind(bind.MultipartFormBodyBinder{}, v)
+	if err := c.Request.ParseMultipartForm(c.Engine.MultipartMemory); err != nil {
+		return bind.BodyError(bind.SourceForm, c.bodyError(err))
+	}
+
+	return c.Bind(bind.MultipartFormBodyBinder{Strict: c.BluePrint().Strict()}, v)
 }
 
 func (c *Context) BindJSON(v any) error {
 	//获取json 格式化插件
 	var serialize = c.BluePrint().JSONSerializer()
 
-	return c.Bind(bind.JsonBodyBinder{Serializer: serialize}, v)
+	return c.BindBody(bind.JsonBodyBinder{Serializer: serialize, Strict: c.BluePrint().Strict()}, v)
 }
 
 func (c *Context) BindXML(v any) error {
 	var serializer = c.BluePrint().XMLSerializer()
 
-	return c.Bind(bind.XmlBodyBinder{Serializer: serializer}, v)
+	return c.BindBody(bind.XmlBodyBinder{Serializer: serializer}, v)
+}
+
+func (c *Context) BindYAML(v any) error {
+	var serializer = c.BluePrint().YAMLSerializer()
+
+	return c.BindBody(bind.SerializerBodyBinder{Serializer: serializer}, v)
+}
+
+func (c *Context) BindTOML(v any) error {
+	var serializer = c.BluePrint().TOMLSerializer()
+
+	return c.BindBody(bind.SerializerBodyBinder{Serializer: serializer}, v)
+}
+
+func (c *Context) BindMsgPack(v any) error {
+	var serializer = c.BluePrint().MsgPackSerializer()
+
+	return c.BindBody(bind.SerializerBodyBinder{Serializer: serializer}, v)
+}
+
+func (c *Context) BindCBOR(v any) error {
+	var serializer = c.BluePrint().CBORSerializer()
+
+	return c.BindBody(bind.SerializerBodyBinder{Serializer: serializer}, v)
+}
+
+// BindProtoBuf v 必须是 proto.Message
+func (c *Context) BindProtoBuf(v any) error {
+	var serializer = c.BluePrint().ProtoBufSerializer()
+
+	return c.BindBody(bind.SerializerBodyBinder{Serializer: serializer}, v)
 }
 
 func (c *Context) BindHeader(v any) error {
@@ -251,18 +408,38 @@ func (c *Context) SetCookie(cookie *http.Cookie) {
 	http.SetCookie(c.ResponseWriter, cookie)
 }
 
+// Render 输出响应, 渲染失败且还没有输出任何内容时状态码保持不变, 调用方仍然可以输出错误响应
 func (c *Context) Render(render Render, v any) error {
-	if c.status != 0 {
-		c.ResponseWriter.WriteHeader(int(c.status))
-		c.status = 0
+	var status = int(c.status)
+	if status == 0 {
+		var err = render.Render(c.ResponseWriter, v)
+		if err == nil {
+			c.written = true
+		}
+
+		return err
 	}
 
-	if !bodyAllowedForStatus(int(c.status)) {
+	if !bodyAllowedForStatus(status) {
+		c.status, c.written = 0, true
+		c.ResponseWriter.WriteHeader(status)
 		return nil
 	}
 
-	c.written = true
-	return render.Render(c.ResponseWriter, v)
+	//状态码延迟到第一次写入时再输出, 否则 Render 设置的 header 不会生效
+	var writer = &statusWriter{ResponseWriter: c.ResponseWriter, status: status}
+	if err := render.Render(writer, v); err != nil {
+		if writer.wroteHeader { //已经输出了部分内容, 无法再修改状态码
+			c.status, c.written = 0, true
+		}
+
+		return err
+	}
+
+	writer.WriteHeader(status)
+	c.status, c.written = 0, true
+
+	return nil
 }
 
 func (c *Context) JSON(data any) error {
@@ -271,12 +448,91 @@ func (c *Context) JSON(data any) error {
 	return c.Render(JsonRender{Serializer: serializer}, data)
 }
 
+// JSONP 回调函数名取自地址栏参数 callback, 没有时等同于 JSON
+func (c *Context) JSONP(data any) error {
+	var serializer = c.BluePrint().JSONSerializer()
+	var callback = c.QueryValue("callback").Text()
+
+	return c.Render(JsonpRender{Callback: callback, Serializer: serializer}, data)
+}
+
+// SecureJSON 输出带防劫持前缀的json
+func (c *Context) SecureJSON(data any) error {
+	var serializer = c.BluePrint().JSONSerializer()
+
+	return c.Render(SecureJsonRender{Serializer: serializer}, data)
+}
+
+// PureJSON 不转义html字符
+func (c *Context) PureJSON(data any) error {
+	return c.Render(PureJsonRender{}, data)
+}
+
+// IndentedJSON 格式化输出, 建议只在调试时使用
+func (c *Context) IndentedJSON(data any) error {
+	return c.Render(IndentedJsonRender{}, data)
+}
+
+// JSONStream 从 channel 中读取元素输出json数组, channel 关闭时结束
+func (c *Context) JSONStream(items <-chan any) error {
+	return c.JSONStreamFunc(func() (any, bool) {
+		var item, ok = <-items
+
+		return item, ok
+	})
+}
+
+// JSONStreamFunc 通过迭代函数输出json数组, next 返回 false 时结束
+func (c *Context) JSONStreamFunc(next func() (any, bool)) error {
+	var serializer = c.BluePrint().JSONSerializer()
+
+	return c.Render(JsonStreamRender{Serializer: serializer, Next: next}, nil)
+}
+
 func (c *Context) XML(v any) error {
 	var serializer = c.BluePrint().XMLSerializer()
 
 	return c.Render(XmlRender{Serializer: serializer}, v)
 }
 
+func (c *Context) YAML(v any) error {
+	var serializer = c.BluePrint().YAMLSerializer()
+
+	return c.Render(YamlRender{Serializer: serializer}, v)
+}
+
+func (c *Context) TOML(v any) error {
+	var serializer = c.BluePrint().TOMLSerializer()
+
+	return c.Render(TomlRender{Serializer: serializer}, v)
+}
+
+func (c *Context) MsgPack(v any) error {
+	var serializer = c.BluePrint().MsgPackSerializer()
+
+	return c.Render(MsgPackRender{Serializer: serializer}, v)
+}
+
+func (c *Context) CBOR(v any) error {
+	var serializer = c.BluePrint().CBORSerializer()
+
+	return c.Render(CborRender{Serializer: serializer}, v)
+}
+
+// ProtoBuf v 必须是 proto.Message
+func (c *Context) ProtoBuf(v any) error {
+	var serializer = c.BluePrint().ProtoBufSerializer()
+
+	return c.Render(ProtoBufRender{Serializer: serializer}, v)
+}
+
+// HTML 渲染 BluePrint 设置的页面模板
+func (c *Context) HTML(status int, name string, data any) error {
+	c.SetStatus(uint(status))
+
+	return c.Render(HTMLRender{Templates: c.BluePrint().HTMLTemplates(), Name: name}, data)
+}
+
 func (c *Context) String(format string, v ...any) error {
 	return c.Render(StringRender{
 		Format: format,
@@ -309,6 +565,16 @@ func (c *Context) ServeContent(name string, modTime time.Time, content io.ReadSe
 	}, nil)
 }
 
+// CSV 以附件形式导出结构体切片, 表头和顺序由 csv tag 决定
+func (c *Context) CSV(filename string, data any) error {
+	return c.Render(CSVRender{FileName: filename, BOM: true}, data)
+}
+
+// CSVRows 逐行导出, next 返回 false 时结束
+func (c *Context) CSVRows(filename string, header []string, next func() ([]string, bool)) error {
+	return c.Render(CSVRender{FileName: filename, BOM: true, Header: header, Rows: next}, nil)
+}
+
 func (c *Context) Write(data []byte) error {
 	var _, err = c.ResponseWriter.Write(data)
 
@@ -354,6 +620,33 @@ func (c *Context) AbortWithString(text string, data ...any) {
 	c.Abort()
 }
 
+// Problem 输出 RFC 7807 错误响应, 客户端接受xml时输出 problem+xml
+func (c *Context) Problem(problem *ProblemDetails) error {
+	if len(problem.Instance) == 0 {
+		problem.Instance = c.Request.URL.Path
+	}
+
+	var xmlAccepted = false
+	var offers = []string{mimeProblemJson, mimeJson, mimeProblemXml, mimeXml, mimeXml2}
+	if mediaType, ok := negotiate(c.Request.Header.Get("Accept"), offers); ok {
+		xmlAccepted = mediaType == mimeProblemXml || mediaType == mimeXml || mediaType == mimeXml2
+	}
+
+	var serializer = c.BluePrint().JSONSerializer()
+	if xmlAccepted {
+		serializer = c.BluePrint().XMLSerializer()
+	}
+
+	c.SetStatus(uint(problem.Status))
+	return c.Render(ProblemRender{Serializer: serializer, XML: xmlAccepted}, problem)
+}
+
+// AbortWithProblem 把错误转换为 RFC 7807 错误响应并中断后续处理
+func (c *Context) AbortWithProblem(err error) {
+	_ = c.Problem(ProblemFromError(err))
+	c.Abort()
+}
+
 func (c *Context) AbortWithStatus(code uint) {
 	c.SetStatus(code)
 	c.Abort()
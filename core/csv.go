@@ -0,0 +1,201 @@
+package core
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	csvTag = "csv"
+	// utf8BOM Excel 需要 BOM 才能正确识别 UTF-8 编码的中文
+	utf8BOM = "\xEF\xBB\xBF"
+)
+
+// csvColumn 结构体字段对应的列
+type csvColumn struct {
+	index  int
+	header string
+	order  int
+}
+
+// csvColumns 根据 csv tag 获取表头和顺序, 例: `csv:"姓名,1"`, `csv:"-"` 忽略该字段
+func csvColumns(t reflect.Type) []csvColumn {
+	var columns []csvColumn
+	for i := 0; i < t.NumField(); i++ {
+		var field = t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		var tag, ok = field.Tag.Lookup(csvTag)
+		if !ok {
+			tag = field.Name
+		}
+
+		var tags = strings.Split(tag, ",")
+		if tags[0] == "-" {
+			continue
+		}
+
+		var column = csvColumn{index: i, header: tags[0], order: i}
+		if len(tags) > 1 {
+			if order, err := strconv.Atoi(strings.TrimSpace(tags[1])); err == nil {
+				column.order = order
+			}
+		}
+
+		columns = append(columns, column)
+	}
+
+	sort.SliceStable(columns, func(i, j int) bool {
+		return columns[i].order < columns[j].order
+	})
+
+	return columns
+}
+
+// csvFormat 字段值转换为字符串
+func csvFormat(value reflect.Value) string {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+
+		value = value.Elem()
+	}
+
+	if t, ok := value.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format("2006-01-02 15:04:05")
+	}
+
+	return fmt.Sprint(value.Interface())
+}
+
+// #---------------------------------------------------
+// CSVRender 输出csv表格, v 为结构体切片, 或者通过 Rows 逐行输出
+type CSVRender struct {
+	FileName   string                  //下载的文件名, 为空时直接在浏览器中显示
+	Comma      rune                    //分隔符, 默认 ','
+	BOM        bool                    //写入 UTF-8 BOM, Excel 打开时中文不乱码
+	Header     []string                //使用 Rows 时的表头
+	Rows       func() ([]string, bool) //行迭代器, 返回 false 时结束, 设置后忽略 v
+	FlushEvery int                     //每输出多少行刷新一次, 默认100
+}
+
+func (c CSVRender) Render(w http.ResponseWriter, v any) error {
+	if c.FlushEvery <= 0 {
+		c.FlushEvery = 100
+	}
+
+	var next, header, err = c.rows(v)
+	if err != nil {
+		return err
+	}
+
+	writeContentType(w, "text/csv;charset=utf-8")
+	if len(c.FileName) > 0 {
+		setAttachment(w, c.FileName)
+	}
+
+	if c.BOM {
+		if _, err = w.Write([]byte(utf8BOM)); err != nil {
+			return err
+		}
+	}
+
+	var writer = csv.NewWriter(w)
+	if c.Comma != 0 {
+		writer.Comma = c.Comma
+	}
+
+	if len(header) > 0 {
+		if err = writer.Write(header); err != nil {
+			return err
+		}
+	}
+
+	var flusher, _ = w.(http.Flusher)
+	for count := 1; ; count++ {
+		var row, ok = next()
+		if !ok {
+			break
+		}
+
+		if err = writer.Write(row); err != nil {
+			return err
+		}
+
+		if count%c.FlushEvery == 0 {
+			writer.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// rows 返回行迭代器和表头
+func (c CSVRender) rows(v any) (func() ([]string, bool), []string, error) {
+	if c.Rows != nil {
+		return c.Rows, c.Header, nil
+	}
+
+	var value = reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, nil, errors.New("csv data should be a slice of struct")
+	}
+
+	var elemType = value.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return nil, nil, errors.New("csv data should be a slice of struct")
+	}
+
+	var columns = csvColumns(elemType)
+	var header = c.Header
+	if len(header) == 0 {
+		for _, column := range columns {
+			header = append(header, column.header)
+		}
+	}
+
+	var index = 0
+	var next = func() ([]string, bool) {
+		for index < value.Len() {
+			var item = reflect.Indirect(value.Index(index))
+			index++
+			if !item.IsValid() { //跳过 nil 元素
+				continue
+			}
+
+			var row = make([]string, len(columns))
+			for i, column := range columns {
+				row[i] = csvFormat(item.Field(column.index))
+			}
+
+			return row, true
+		}
+
+		return nil, false
+	}
+
+	return next, header, nil
+}
@@ -1,88 +1,331 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"gin-core/common"
 	"io"
+	"io/fs"
 	"mime/multipart"
+	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
+// FileInfo 已存储文件的信息
+type FileInfo struct {
+	Name    string    `json:"name"` //存储名称, 使用 / 分隔
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// FileStorage 文件存储器, name 为 Save 返回的存储名称: 相对于 MediaRoot 的路径, 使用 / 分隔, 不是磁盘上的绝对路径,
+// Open, Delete, Stat, Exists, URL 都使用该名称; 文件不存在时返回的错误满足 errors.Is(err, fs.ErrNotExist)
 type FileStorage interface {
 	Save(fileHeader *multipart.FileHeader) (string, error)
+	Open(name string) (io.ReadSeekCloser, error)
+	Delete(name string) error
+	Stat(name string) (FileInfo, error)
+	List(prefix string) ([]FileInfo, error)
+	Exists(name string) (bool, error)
+	URL(name string) string
 }
 
 var _ FileStorage = &LocalFileStorage{}
 
 type LocalFileStorage struct {
 	MediaRoot string
+	BaseURL   string         //访问文件的URL前缀, 例: /media/ 或 https://cdn.example.com/
+	Naming    NamingStrategy //存储名称的生成方式, 默认 SanitizedName
 	lock      sync.RWMutex
 }
 
-func fileExists(filePath string) (bool, error) {
-	if _, err := os.Stat(filePath); err != nil {
-		return os.IsNotExist(err), nil
-	}
-
-	return true, nil
-}
+const (
+	tempPrefix      = ".upload-" //保存过程中的临时文件, List 时跳过
+	maxNameAttempts = 10         //名称冲突时最多尝试的次数
+)
 
 func (l *LocalFileStorage) SetMediaRouter(mediaRoot string) {
 	l.MediaRoot = mediaRoot
 }
 
-// getAlternativeName 随机命名文件
-func (l *LocalFileStorage) getAlternativeName(filename string) (string, error) {
-	l.lock.RLock()
+// Save 保存上传的文件, 返回相对 MediaRoot 的存储名称.
+// 先写入同目录的临时文件再链接到目标名称, 不会覆盖已有文件, 也不会留下写了一半的文件.
+// 名称中包含内容的 SHA-256 时(例: HashName)视为按内容命名, 内容相同的文件直接返回已有的名称
+func (l *LocalFileStorage) Save(fileHeader *multipart.FileHeader) (string, error) {
+	var name, err = storageName(l.Naming, fileHeader)
+	if err != nil {
+		return "", err
+	}
+
+	var dst = l.resolve(name)
+	var digest = sha256.New()
+	var tmp string
+	if tmp, _, err = writeTemp(fileHeader, filepath.Dir(dst), digest); err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp)
+
+	var hash = hex.EncodeToString(digest.Sum(nil))
+	var contentAddressed = strings.Contains(name, hash)
+
+	l.lock.Lock()
 	defer l.lock.Unlock()
 
-	for {
-		var dst = path.Join(l.MediaRoot, filename)
-		var exist, err = fileExists(dst)
-		if err != nil {
-			return "", err
+	for attempt := 0; attempt < maxNameAttempts; attempt++ {
+		if err = os.Link(tmp, dst); err == nil {
+			return l.relative(dst)
+		}
+
+		if !os.IsExist(err) {
+			//文件系统不支持硬链接时改用重命名, 目标已存在按名称冲突处理, 其他错误直接返回
+			var _, err01 = os.Lstat(dst)
+			switch {
+			case os.IsNotExist(err01):
+				if err = os.Rename(tmp, dst); err != nil {
+					return "", err
+				}
+
+				return l.relative(dst)
+			case err01 != nil:
+				return "", err01
+			}
 		}
 
-		if !exist {
-			return dst, err
+		//已有文件的内容也要一致, 客户端可以伪造包含哈希的文件名
+		if contentAddressed {
+			if existing, err01 := fileHash(dst); err01 == nil && existing == hash {
+				return l.relative(dst)
+			}
+
+			contentAddressed = false
 		}
 
-		filename = common.GetRandomString(7) + filename
+		dst = l.resolve(alternativeName(name))
 	}
+
+	return "", fmt.Errorf("no available name for %q after %d attempts", name, maxNameAttempts)
 }
 
-func (l *LocalFileStorage) Save(fileHeader *multipart.FileHeader) (string, error) {
-	var src, err = fileHeader.Open()
+// storageName 使用命名方式生成存储名称, 默认 SanitizedName
+func storageName(naming NamingStrategy, fileHeader *multipart.FileHeader) (string, error) {
+	if naming == nil {
+		naming = SanitizedName
+	}
+
+	var name, err = naming(fileHeader)
 	if err != nil {
 		return "", err
 	}
+
+	if name = cleanName(name); len(name) == 0 {
+		return "", fmt.Errorf("invalid file name %q", fileHeader.Filename)
+	}
+
+	return name, nil
+}
+
+// alternativeName 名称冲突时在扩展名前加随机字符串, 例: avatar-a1B2c3D.png
+func alternativeName(name string) string {
+	var ext = path.Ext(name)
+
+	return strings.TrimSuffix(name, ext) + "-" + common.GetRandomString(7) + ext
+}
+
+// writeTemp 把上传的文件写入 dir 目录下的临时文件, extra 不为 nil 时同时写入, 例: 计算哈希
+func writeTemp(fileHeader *multipart.FileHeader, dir string, extra io.Writer) (string, int64, error) {
+	var src, err = fileHeader.Open()
+	if err != nil {
+		return "", 0, err
+	}
 	defer src.Close()
 
-	if len(l.MediaRoot) > 0 {
-		if _, err = os.Stat(l.MediaRoot); err != nil && os.IsExist(err) {
-			if err = os.MkdirAll(l.MediaRoot, 0666); err != nil {
-				return "", err
-			}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, err
+	}
+
+	var out *os.File
+	if out, err = os.CreateTemp(dir, tempPrefix+"*"); err != nil {
+		return "", 0, err
+	}
+
+	var writer io.Writer = out
+	if extra != nil {
+		writer = io.MultiWriter(out, extra)
+	}
+
+	//CreateTemp 创建的文件权限是 0600
+	var size int64
+	if err = out.Chmod(0644); err == nil {
+		if size, err = io.Copy(writer, src); err == nil {
+			err = out.Sync()
 		}
 	}
 
-	dst, err := l.getAlternativeName(fileHeader.Filename)
+	if err01 := out.Close(); err == nil {
+		err = err01
+	}
+
+	if err != nil {
+		_ = os.Remove(out.Name())
+		return "", 0, err
+	}
+
+	return out.Name(), size, nil
+}
+
+// fileHash 文件内容的 SHA-256
+func fileHash(filePath string) (string, error) {
+	var file, err = os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var digest = sha256.New()
+	if _, err = io.Copy(digest, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// mediaRoot 存储的根目录, 默认为当前目录
+func mediaRoot(root string) string {
+	if len(root) == 0 {
+		return "."
+	}
+
+	return root
+}
+
+// joinURL 拼接URL前缀和已转义的路径, 前缀默认为 /
+func joinURL(base, escaped string) string {
+	if len(base) == 0 {
+		base = "/"
+	}
+
+	return strings.TrimSuffix(base, "/") + "/" + escaped
+}
+
+// cleanName 清理存储名称, 去掉开头的 / 和名称中的 ..
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// resolve 存储名称转换成文件路径, 名称中的 .. 不能跳出 MediaRoot
+func (l *LocalFileStorage) resolve(name string) string {
+	return filepath.Join(l.root(), filepath.FromSlash(cleanName(name)))
+}
+
+// relative 文件路径转换成存储名称
+func (l *LocalFileStorage) relative(filePath string) (string, error) {
+	var name, err = filepath.Rel(l.root(), filePath)
 	if err != nil {
 		return "", err
 	}
 
+	return filepath.ToSlash(name), nil
+}
+
+func (l *LocalFileStorage) root() string {
+	return mediaRoot(l.MediaRoot)
+}
+
+// Open 打开已存储的文件
+func (l *LocalFileStorage) Open(name string) (io.ReadSeekCloser, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return os.Open(l.resolve(name))
+}
+
+// Delete 删除已存储的文件
+func (l *LocalFileStorage) Delete(name string) error {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
-	out, err := os.Create(dst)
+	return os.Remove(l.resolve(name))
+}
+
+// Stat 获取已存储文件的信息
+func (l *LocalFileStorage) Stat(name string) (FileInfo, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	var info, err = os.Stat(l.resolve(name))
 	if err != nil {
-		return "", err
+		return FileInfo{}, err
 	}
 
-	defer out.Close()
+	if info.IsDir() {
+		return FileInfo{}, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return FileInfo{Name: cleanName(name), Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// List 列出名称以 prefix 开头的所有文件, 按名称排序
+func (l *LocalFileStorage) List(prefix string) ([]FileInfo, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	var files = make([]FileInfo, 0)
+	var err = filepath.WalkDir(l.root(), func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), tempPrefix) {
+			return err
+		}
 
-	_, err = io.Copy(out, src)
+		var name, err01 = l.relative(filePath)
+		if err01 != nil || !strings.HasPrefix(name, prefix) {
+			return err01
+		}
+
+		var info, err02 = d.Info()
+		if err02 != nil {
+			return err02
+		}
+
+		files = append(files, FileInfo{Name: name, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if os.IsNotExist(err) { //还没有保存过文件
+		return files, nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	return files, err
+}
+
+// Exists 文件是否存在
+func (l *LocalFileStorage) Exists(name string) (bool, error) {
+	var _, err = l.Stat(name)
+	if err == nil {
+		return true, nil
+	}
+
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// URL 文件的访问地址, 每一段路径都会转义
+func (l *LocalFileStorage) URL(name string) string {
+	var segments = strings.Split(cleanName(name), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
 
-	return dst, err
+	return joinURL(l.BaseURL, strings.Join(segments, "/"))
 }
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+)
+
+// HTMLTemplates 基于 html/template 的模板集合,
+// 每个页面会和布局、公共片段一起解析, 页面通过 {{define "content"}} 覆盖布局中的区块
+type HTMLTemplates struct {
+	FS       fs.FS            //模板所在的文件系统, 目录使用 os.DirFS, 也可以是 embed.FS
+	Pages    []string         //页面模板, 支持通配符, 例: pages/*.html
+	Layouts  []string         //布局模板, 例: layouts/*.html
+	Partials []string         //公共片段, 例: partials/*.html
+	Layout   string           //执行的布局模板名称, 为空或页面没有该模板时执行页面本身
+	FuncMap  template.FuncMap //自定义模板函数
+	Debug    bool             //调试模式, 每次渲染都会重新加载模板
+
+	lock      sync.RWMutex
+	templates map[string]*template.Template //页面路径 => 模板
+}
+
+// NewHTMLTemplates 从文件系统加载模板
+func NewHTMLTemplates(fsys fs.FS, pages ...string) *HTMLTemplates {
+	return &HTMLTemplates{FS: fsys, Pages: pages}
+}
+
+// NewHTMLTemplatesFromDir 从目录加载模板
+func NewHTMLTemplatesFromDir(dir string, pages ...string) *HTMLTemplates {
+	return NewHTMLTemplates(os.DirFS(dir), pages...)
+}
+
+// Load 解析所有的页面模板
+func (h *HTMLTemplates) Load() error {
+	if h.FS == nil {
+		return errors.New("`FS` can not be nil")
+	}
+
+	var shared []string
+	shared = append(shared, h.Layouts...)
+	shared = append(shared, h.Partials...)
+
+	var templates = make(map[string]*template.Template, 0)
+	for _, pattern := range h.Pages {
+		var pages, err = fs.Glob(h.FS, pattern)
+		if err != nil {
+			return err
+		}
+
+		for _, page := range pages {
+			var tpl = template.New(path.Base(page)).Funcs(h.FuncMap)
+			if len(shared) > 0 {
+				if tpl, err = tpl.ParseFS(h.FS, shared...); err != nil {
+					return err
+				}
+			}
+
+			//页面最后解析, 同名区块以页面为准
+			if tpl, err = tpl.ParseFS(h.FS, page); err != nil {
+				return err
+			}
+
+			templates[page] = tpl
+		}
+	}
+
+	h.lock.Lock()
+	h.templates = templates
+	h.lock.Unlock()
+
+	return nil
+}
+
+// Lookup 获取页面模板, 调试模式下会重新加载
+func (h *HTMLTemplates) Lookup(name string) (*template.Template, error) {
+	h.lock.RLock()
+	var loaded = h.templates != nil
+	h.lock.RUnlock()
+
+	if h.Debug || !loaded {
+		if err := h.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	var tpl, ok = h.templates[name]
+	if !ok {
+		return nil, errors.New("html template " + name + " does not exist")
+	}
+
+	return tpl, nil
+}
+
+// Execute 渲染页面到缓冲区, 渲染失败时不会输出不完整的页面
+func (h *HTMLTemplates) Execute(name string, data any) (*bytes.Buffer, error) {
+	var tpl, err = h.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry = path.Base(name)
+	if len(h.Layout) > 0 && tpl.Lookup(h.Layout) != nil {
+		entry = h.Layout
+	}
+
+	var buf = new(bytes.Buffer)
+	if err = tpl.ExecuteTemplate(buf, entry, data); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// #---------------------------------------------------
+type HTMLRender struct {
+	Templates *HTMLTemplates
+	Name      string //页面路径, 例: pages/index.html
+}
+
+func (h HTMLRender) Render(w http.ResponseWriter, v any) error {
+	if h.Templates == nil {
+		return errors.New("`HTMLTemplates` can not be nil")
+	}
+
+	var buf, err = h.Templates.Execute(h.Name, v)
+	if err != nil {
+		return err
+	}
+
+	writeContentType(w, "text/html;charset=utf-8")
+	_, err = buf.WriteTo(w)
+
+	return err
+}
@@ -30,3 +30,32 @@ func bodyAllowedForStatus(status int) bool {
 
 	return true
 }
+
+// statusWriter 第一次写入时才输出状态码
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(data []byte) (int, error) {
+	w.WriteHeader(w.status)
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *statusWriter) Flush() {
+	w.WriteHeader(w.status)
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NamingStrategy 决定上传文件的存储名称, 返回使用 / 分隔的相对路径,
+// 名称会再经过 LocalFileStorage 的清理, 不能跳出 MediaRoot
+type NamingStrategy func(fileHeader *multipart.FileHeader) (string, error)
+
+// maxFilenameLength 大多数文件系统的文件名最大字节数
+const maxFilenameLength = 255
+
+// SanitizeFilename 清理客户端提交的文件名: 去掉目录, 控制字符和文件系统保留字符
+func SanitizeFilename(filename string) string {
+	//不同系统的路径分隔符都去掉
+	if idx := strings.LastIndexAny(filename, `/\`); idx >= 0 {
+		filename = filename[idx+1:]
+	}
+
+	var builder strings.Builder
+	for _, r := range filename {
+		switch {
+		case r == utf8.RuneError, unicode.IsControl(r), strings.ContainsRune(`<>:"|?*`, r):
+			builder.WriteRune('_')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	filename = strings.Trim(builder.String(), ". ")
+	if len(filename) == 0 {
+		return "file"
+	}
+
+	//超长时保留扩展名
+	if len(filename) > maxFilenameLength {
+		var ext = path.Ext(filename)
+		if len(ext) > 16 {
+			ext = ""
+		}
+
+		var base = filename[:maxFilenameLength-len(ext)]
+		for !utf8.ValidString(base) {
+			base = base[:len(base)-1]
+		}
+
+		filename = base + ext
+	}
+
+	return filename
+}
+
+// safeExt 清理后的小写扩展名
+func safeExt(filename string) string {
+	var ext = strings.ToLower(path.Ext(SanitizeFilename(filename)))
+	if len(ext) > 16 || strings.ContainsAny(ext, " _") {
+		return ""
+	}
+
+	return ext
+}
+
+// SanitizedName 使用清理后的原始文件名, 默认的命名方式
+func SanitizedName(fileHeader *multipart.FileHeader) (string, error) {
+	return SanitizeFilename(fileHeader.Filename), nil
+}
+
+// UUIDName 使用随机的 UUID v4 命名, 保留扩展名
+func UUIDName(fileHeader *multipart.FileHeader) (string, error) {
+	var b = make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]) + safeExt(fileHeader.Filename), nil
+}
+
+// HashName 使用文件内容的 SHA-256 命名, 保留扩展名
+func HashName(fileHeader *multipart.FileHeader) (string, error) {
+	var src, err = fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	var hash = sha256.New()
+	if _, err = io.Copy(hash, src); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)) + safeExt(fileHeader.Filename), nil
+}
+
+// DatePartitioned 按上传日期分目录, 例: 2026/10/16/avatar.png
+func DatePartitioned(naming NamingStrategy) NamingStrategy {
+	return func(fileHeader *multipart.FileHeader) (string, error) {
+		var name, err = naming(fileHeader)
+		if err != nil {
+			return "", err
+		}
+
+		return time.Now().Format("2006/01/02/") + name, nil
+	}
+}
@@ -0,0 +1,246 @@
+package core
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNotAcceptable 没有任何一种格式满足客户端的 Accept
+var ErrNotAcceptable = errors.New("not acceptable")
+
+// mediaTypeAliases 同一种格式的其他媒体类型
+var mediaTypeAliases = map[string]string{
+	mimeXml2:      mimeXml,
+	mimeYaml2:     mimeYaml,
+	mimeYaml3:     mimeYaml,
+	mimeMsgPack2:  mimeMsgPack,
+	mimeProtoBuf2: mimeProtoBuf,
+}
+
+// acceptRange Accept 头中的单个媒体类型范围
+type acceptRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// specificity 越具体优先级越高: */* < text/* < text/html
+func (a acceptRange) specificity() int {
+	switch {
+	case a.typ == "*":
+		return 0
+	case a.subtype == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (a acceptRange) match(mediaType string) bool {
+	var typ, subtype, _ = strings.Cut(mediaType, "/")
+	if a.typ != "*" && a.typ != typ {
+		return false
+	}
+
+	return a.subtype == "*" || a.subtype == subtype
+}
+
+// parseAccept 解析 Accept 头, 例: text/html, application/xml;q=0.9, */*;q=0.8
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, item := range strings.Split(header, ",") {
+		var mediaType, params, err = mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+
+		var typ, subtype, ok = strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+
+		var q = 1.0
+		if value, exist := params["q"]; exist {
+			if q, err = strconv.ParseFloat(value, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	//越具体的范围排在越前面, 匹配时取第一个
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+
+	return ranges
+}
+
+// negotiate 选出客户端接受程度最高的格式, q值相同时按 offers 的顺序
+func negotiate(header string, offers []string) (string, bool) {
+	if len(strings.TrimSpace(header)) == 0 {
+		header = "*/*"
+	}
+
+	var ranges = parseAccept(header)
+	var best, bestQ = "", 0.0
+	for _, offer := range offers {
+		for _, r := range ranges {
+			if !r.match(strings.ToLower(offer)) {
+				continue
+			}
+
+			if r.q > bestQ {
+				best, bestQ = offer, r.q
+			}
+
+			break
+		}
+	}
+
+	return best, bestQ > 0
+}
+
+// renderFor 根据媒体类型选择 Render
+func (c *Context) renderFor(mediaType string) Render {
+	var name = mediaType
+	if alias, ok := mediaTypeAliases[name]; ok {
+		name = alias
+	}
+
+	var serializer = c.BluePrint().Serializer(name)
+	if serializer == nil {
+		return nil
+	}
+
+	switch name {
+	case mimeJson:
+		return JsonRender{Serializer: serializer}
+	case mimeXml:
+		return XmlRender{Serializer: serializer}
+	case mimeYaml:
+		return YamlRender{Serializer: serializer}
+	case mimeToml:
+		return TomlRender{Serializer: serializer}
+	case mimeMsgPack:
+		return MsgPackRender{Serializer: serializer}
+	case mimeCbor:
+		return CborRender{Serializer: serializer}
+	case mimeProtoBuf:
+		return ProtoBufRender{Serializer: serializer}
+	default:
+		return SerializerRender{ContentType: mediaType, Serializer: serializer}
+	}
+}
+
+// encodable 格式能否编码该值, protobuf 只支持 proto.Message, toml 只支持结构体和map, xml 不支持map
+func encodable(mediaType string, data any) bool {
+	if alias, ok := mediaTypeAliases[mediaType]; ok {
+		mediaType = alias
+	}
+
+	var kind = reflect.Invalid
+	if v := reflect.ValueOf(data); v.IsValid() {
+		kind = reflect.Indirect(v).Kind()
+	}
+
+	switch mediaType {
+	case mimeProtoBuf:
+		var _, ok = data.(proto.Message)
+		return ok
+	case mimeToml:
+		return kind == reflect.Struct || kind == reflect.Map
+	case mimeXml:
+		return kind != reflect.Map && kind != reflect.Invalid
+	default:
+		return true
+	}
+}
+
+// Negotiate 根据 Accept 选择输出格式, offers 为空时使用 BluePrint 注册的所有能编码 data 的序列化器,
+// 没有匹配的格式时返回 406
+func (c *Context) Negotiate(data any, offers ...string) error {
+	if len(offers) == 0 {
+		for _, mediaType := range c.BluePrint().MediaTypes() {
+			if encodable(mediaType, data) {
+				offers = append(offers, mediaType)
+			}
+		}
+	}
+
+	c.ResponseWriter.Header().Add("Vary", "Accept")
+
+	var mediaType, ok = negotiate(c.Request.Header.Get("Accept"), offers)
+	if !ok {
+		c.SetStatus(http.StatusNotAcceptable)
+		return ErrNotAcceptable
+	}
+
+	var render = c.renderFor(mediaType)
+	if render == nil {
+		return errors.New("no serializer registered for " + mediaType)
+	}
+
+	return c.Render(render, data)
+}
+
+// parseAcceptLanguage 解析 Accept-Language 头, 按q值从高到低排列, 例: zh-CN,zh;q=0.9,en;q=0.8
+func parseAcceptLanguage(header string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+
+	var languages []language
+	for _, item := range strings.Split(header, ",") {
+		var tag, params, _ = strings.Cut(strings.TrimSpace(item), ";")
+		tag = strings.TrimSpace(tag)
+		if len(tag) == 0 || tag == "*" {
+			continue
+		}
+
+		var q = 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			var err error
+			if q, err = strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64); err != nil || q <= 0 || q > 1 {
+				continue
+			}
+		}
+
+		languages = append(languages, language{tag: tag, q: q})
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].q > languages[j].q
+	})
+
+	var tags = make([]string, len(languages))
+	for i, lang := range languages {
+		tags[i] = lang.tag
+	}
+
+	return tags
+}
+
+// Locales 客户端接受的语言, 按 Accept-Language 的优先级排列
+func (c *Context) Locales() []string {
+	return parseAcceptLanguage(c.Request.Header.Get("Accept-Language"))
+}
+
+// Locale 客户端最优先的语言, 没有 Accept-Language 时为空
+func (c *Context) Locale() string {
+	var locales = c.Locales()
+	if len(locales) == 0 {
+		return ""
+	}
+
+	return locales[0]
+}
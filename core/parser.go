@@ -1,6 +1,12 @@
 package core
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
 
 const (
 	minePostForm          = "application/x-www-form-urlencoded"
@@ -10,23 +16,122 @@ const (
 	mimeXml               = "application/xml"
 	mimeXml2              = "text/xml"
 	mimeHtml              = "text/html"
+	mimeYaml              = "application/yaml"
+	mimeYaml2             = "application/x-yaml"
+	mimeYaml3             = "text/yaml"
+	mimeToml              = "application/toml"
+	mimeMsgPack           = "application/msgpack"
+	mimeMsgPack2          = "application/x-msgpack"
+	mimeCbor              = "application/cbor"
+	mimeProtoBuf          = "application/x-protobuf"
+	mimeProtoBuf2         = "application/protobuf"
 )
 
+// ErrUnsupportedMediaType 请求体的媒体类型没有对应的解析器
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// structuredSuffixes 结构化后缀对应的基础媒体类型, 例: application/vnd.api+json
+var structuredSuffixes = map[string]string{
+	"json": mimeJson,
+	"xml":  mimeXml,
+	"yaml": mimeYaml,
+	"cbor": mimeCbor,
+}
+
+// textCharsets 文本格式支持的字符集
+var textCharsets = map[string]bool{
+	"":         true,
+	"utf-8":    true,
+	"utf8":     true,
+	"us-ascii": true,
+}
+
 type Parser interface {
 	Parse(ctx *Context, v any) error
-	Match(ctx *Context) bool
 }
 
-type Parsers []Parser
+// Parsers 解析器注册表, 键为小写的媒体类型(不含参数)
+type Parsers map[string]Parser
+
+// NewParsers 默认支持的所有格式
+func NewParsers() Parsers {
+	return Parsers{
+		mimeJson:              JsonParser{},
+		minePostForm:          FormParser{},
+		mimeMultipartPostForm: MultipartFormParser{},
+		mimeXml:               XMLParser{},
+		mimeXml2:              XMLParser{},
+		mimeYaml:              YAMLParser{},
+		mimeYaml2:             YAMLParser{},
+		mimeYaml3:             YAMLParser{},
+		mimeToml:              TOMLParser{},
+		mimeMsgPack:           MsgPackParser{},
+		mimeMsgPack2:          MsgPackParser{},
+		mimeCbor:              CBORParser{},
+		mimeProtoBuf:          ProtoBufParser{},
+		mimeProtoBuf2:         ProtoBufParser{},
+	}
+}
+
+// Register 注册媒体类型对应的解析器, 可用于厂商自定义类型
+func (p Parsers) Register(mediaType string, parser Parser) {
+	if parser == nil {
+		panic("parser can not be nil")
+	}
 
-func (p Parsers) Parse(ctx *Context, v any) error {
-	for _, parser := range p {
-		if parser.Match(ctx) {
-			return parser.Parse(ctx, v)
+	p[strings.ToLower(mediaType)] = parser
+}
+
+// Lookup 根据 Content-Type 查找解析器, 支持 +json/+xml 等结构化后缀
+func (p Parsers) Lookup(contentType string) (Parser, error) {
+	if len(contentType) == 0 {
+		return nil, fmt.Errorf("%w: missing Content-Type", ErrUnsupportedMediaType)
+	}
+
+	var mediaType, params, err = mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+	}
+
+	var parser, ok = p[mediaType]
+	if !ok {
+		if idx := strings.LastIndex(mediaType, "+"); idx != -1 {
+			if base, exist := structuredSuffixes[mediaType[idx+1:]]; exist {
+				parser, ok = p[base]
+			}
 		}
 	}
 
-	return QueryParser{}.Parse(ctx, v)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+	}
+
+	//multipart 的 charset 没有意义, 二进制格式不会带 charset
+	if !textCharsets[strings.ToLower(params["charset"])] {
+		return nil, fmt.Errorf("%w: charset %s", ErrUnsupportedMediaType, params["charset"])
+	}
+
+	return parser, nil
+}
+
+// Parse 没有请求体时从地址栏参数绑定, 有请求体但找不到解析器时返回 415
+func (p Parsers) Parse(ctx *Context, v any) error {
+	if !hasBody(ctx.Request) {
+		return QueryParser{}.Parse(ctx, v)
+	}
+
+	var parser, err = p.Lookup(ctx.ContentType())
+	if err != nil {
+		ctx.SetStatus(http.StatusUnsupportedMediaType)
+		return err
+	}
+
+	return parser.Parse(ctx, v)
+}
+
+// hasBody 请求是否带有请求体, 分块传输时长度未知
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
 }
 
 type FormParser struct {
@@ -36,10 +141,6 @@ func (f FormParser) Parse(ctx *Context, v any) error {
 	return ctx.BindForm(v)
 }
 
-func (f FormParser) Match(ctx *Context) bool {
-	return strings.Contains(strings.ToLower(ctx.ContentType()), minePostForm)
-}
-
 type JsonParser struct {
 }
 
@@ -47,10 +148,6 @@ func (j JsonParser) Parse(ctx *Context, v any) error {
 	return ctx.BindJSON(v)
 }
 
-func (j JsonParser) Match(ctx *Context) bool {
-	return strings.Contains(strings.ToLower(ctx.ContentType()), mimeJson)
-}
-
 type MultipartFormParser struct {
 }
 
@@ -58,10 +155,6 @@ func (m MultipartFormParser) Parse(ctx *Context, v any) error {
 	return ctx.BindMultipartForm(v)
 }
 
-func (m MultipartFormParser) Match(ctx *Context) bool {
-	return strings.Contains(strings.ToLower(ctx.ContentType()), mimeMultipartPostForm)
-}
-
 type XMLParser struct {
 }
 
@@ -69,10 +162,39 @@ func (x XMLParser) Parse(ctx *Context, v any) error {
 	return ctx.BindXML(v)
 }
 
-func (x XMLParser) Match(ctx *Context) bool {
-	var cType = strings.ToLower(ctx.ContentType())
+type YAMLParser struct {
+}
+
+func (y YAMLParser) Parse(ctx *Context, v any) error {
+	return ctx.BindYAML(v)
+}
 
-	return strings.Contains(cType, mimeXml) || strings.Contains(cType, mimeXml2)
+type TOMLParser struct {
+}
+
+func (t TOMLParser) Parse(ctx *Context, v any) error {
+	return ctx.BindTOML(v)
+}
+
+type MsgPackParser struct {
+}
+
+func (m MsgPackParser) Parse(ctx *Context, v any) error {
+	return ctx.BindMsgPack(v)
+}
+
+type CBORParser struct {
+}
+
+func (c CBORParser) Parse(ctx *Context, v any) error {
+	return ctx.BindCBOR(v)
+}
+
+type ProtoBufParser struct {
+}
+
+func (p ProtoBufParser) Parse(ctx *Context, v any) error {
+	return ctx.BindProtoBuf(v)
 }
 
 type QueryParser struct {
@@ -81,7 +203,3 @@ type QueryParser struct {
 func (q QueryParser) Parse(ctx *Context, v any) error {
 	return ctx.BindQuery(v)
 }
-
-func (q QueryParser) Match(ctx *Context) bool {
-	return true
-}
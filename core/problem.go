@@ -0,0 +1,234 @@
+package core
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"gin-core/core/bind"
+	"gin-core/core/color"
+	"gin-core/core/validators"
+	"io/fs"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	mimeProblemJson = "application/problem+json"
+	mimeProblemXml  = "application/problem+xml"
+	problemXmlns    = "urn:ietf:rfc:7807"
+)
+
+// ProblemError 字段级别的错误
+type ProblemError struct {
+	Field   string `json:"field" xml:"field"`
+	Rule    string `json:"rule,omitempty" xml:"rule,omitempty"`
+	Message string `json:"message" xml:"message"`
+}
+
+// ProblemDetails RFC 7807 错误响应
+type ProblemDetails struct {
+	Type       string         `json:"type,omitempty" xml:"type,omitempty"`
+	Title      string         `json:"title,omitempty" xml:"title,omitempty"`
+	Status     int            `json:"status,omitempty" xml:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty" xml:"instance,omitempty"`
+	Errors     []ProblemError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+	Extensions map[string]any `json:"-" xml:"-"` //扩展字段, 输出时和标准字段平级
+}
+
+// NewProblem 创建错误响应, title 默认为状态码对应的描述
+func NewProblem(status int, detail string) *ProblemDetails {
+	return &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *ProblemDetails) Error() string {
+	if len(p.Detail) > 0 {
+		return p.Title + ": " + p.Detail
+	}
+
+	return p.Title
+}
+
+// With 添加扩展字段
+func (p *ProblemDetails) With(key string, value any) *ProblemDetails {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any, 0)
+	}
+
+	p.Extensions[key] = value
+	return p
+}
+
+// problemAlias 避免 MarshalJSON 递归调用
+type problemAlias ProblemDetails
+
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	var data, err = json.Marshal(problemAlias(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	var fields = make(map[string]any, len(p.Extensions)+6)
+	for key, value := range p.Extensions {
+		fields[key] = value
+	}
+
+	//标准字段不能被扩展字段覆盖
+	var standard map[string]any
+	if err = json.Unmarshal(data, &standard); err != nil {
+		return nil, err
+	}
+
+	for key, value := range standard {
+		fields[key] = value
+	}
+
+	return json.Marshal(fields)
+}
+
+func (p ProblemDetails) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Space: problemXmlns, Local: "problem"}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	var alias = problemAlias(p)
+	var fields = []struct {
+		name  string
+		value any
+		empty bool
+	}{
+		{"type", alias.Type, len(alias.Type) == 0},
+		{"title", alias.Title, len(alias.Title) == 0},
+		{"status", alias.Status, alias.Status == 0},
+		{"detail", alias.Detail, len(alias.Detail) == 0},
+		{"instance", alias.Instance, len(alias.Instance) == 0},
+	}
+	for _, field := range fields {
+		if field.empty {
+			continue
+		}
+
+		if err := e.EncodeElement(field.value, xml.StartElement{Name: xml.Name{Local: field.name}}); err != nil {
+			return err
+		}
+	}
+
+	if len(alias.Errors) > 0 {
+		var errorsElement = struct {
+			Errors []ProblemError `xml:"error"`
+		}{alias.Errors}
+		if err := e.EncodeElement(errorsElement, xml.StartElement{Name: xml.Name{Local: "errors"}}); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range alias.Extensions {
+		if err := e.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: key}}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// ProblemFromError 把框架内的错误转换成 ProblemDetails,
+// 绑定错误为400, 验证错误为422, 未知错误为500且不暴露错误详情
+func ProblemFromError(err error) *ProblemDetails {
+	var problem *ProblemDetails
+	if errors.As(err, &problem) {
+		return problem
+	}
+
+	var bindErrs bind.BindErrors
+	var fieldErr *bind.FieldError
+	var validationErrs validators.ValidationErrors
+	var validationErr *validators.ValidationError
+	switch {
+	case errors.As(err, &bindErrs):
+		problem = NewProblem(http.StatusBadRequest, "request parameters could not be bound")
+		for _, e := range bindErrs {
+			problem.Errors = append(problem.Errors, bindProblemError(e))
+		}
+	case errors.As(err, &fieldErr):
+		problem = NewProblem(http.StatusBadRequest, "request parameters could not be bound")
+		problem.Errors = append(problem.Errors, bindProblemError(fieldErr))
+	case isDecodeError(err):
+		problem = NewProblem(http.StatusBadRequest, "request body could not be decoded")
+		var fieldErr01, _ = bind.BodyError(bind.SourceBody, err).(*bind.FieldError)
+		if fieldErr01 != nil {
+			problem.Errors = append(problem.Errors, bindProblemError(fieldErr01))
+		}
+	case errors.As(err, &validationErrs):
+		problem = NewProblem(http.StatusUnprocessableEntity, "request parameters are invalid")
+		for _, e := range validationErrs {
+			problem.Errors = append(problem.Errors, validationProblemError(e))
+		}
+	case errors.As(err, &validationErr):
+		problem = NewProblem(http.StatusUnprocessableEntity, "request parameters are invalid")
+		problem.Errors = append(problem.Errors, validationProblemError(validationErr))
+	case errors.Is(err, bind.ErrBodyTooLarge):
+		problem = NewProblem(http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, ErrUnsupportedMediaType):
+		problem = NewProblem(http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, ErrNotAcceptable):
+		problem = NewProblem(http.StatusNotAcceptable, err.Error())
+	case errors.Is(err, fs.ErrNotExist):
+		problem = NewProblem(http.StatusNotFound, "file not found")
+	default:
+		problem = NewProblem(http.StatusInternalServerError, "")
+	}
+
+	return problem
+}
+
+// isDecodeError 没有经过绑定器的解析错误, 例: 直接调用 Serializer.Decode
+func isDecodeError(err error) bool {
+	var jsonSyntaxErr *json.SyntaxError
+	var jsonTypeErr *json.UnmarshalTypeError
+	var xmlSyntaxErr *xml.SyntaxError
+	var yamlTypeErr *yaml.TypeError
+
+	return errors.As(err, &jsonSyntaxErr) || errors.As(err, &jsonTypeErr) ||
+		errors.As(err, &xmlSyntaxErr) || errors.As(err, &yamlTypeErr) ||
+		errors.Is(err, color.ErrTrailingData) || errors.Is(err, bind.ErrUnknownField)
+}
+
+func bindProblemError(err *bind.FieldError) ProblemError {
+	var field = err.Field
+	if len(field) == 0 {
+		field = err.Key
+	}
+	if len(field) == 0 { //请求体的语法错误没有具体的字段
+		field = err.Source
+	}
+
+	return ProblemError{Field: field, Rule: "bind", Message: err.Message}
+}
+
+func validationProblemError(err *validators.ValidationError) ProblemError {
+	return ProblemError{Field: err.Path, Rule: err.Rule, Message: err.Message}
+}
+
+// #---------------------------------------------------
+// ProblemRender 输出 application/problem+json 或 application/problem+xml
+type ProblemRender struct {
+	Serializer color.Serializer
+	XML        bool
+}
+
+func (p ProblemRender) Render(w http.ResponseWriter, v any) error {
+	if p.XML {
+		writeContentType(w, mimeProblemXml+";charset=utf-8")
+	} else {
+		writeContentType(w, mimeProblemJson+";charset=utf-8")
+	}
+
+	return p.Serializer.Encode(w, v)
+}
@@ -0,0 +1,249 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentRef 逻辑名称和内容哈希的对应关系
+type ContentRef struct {
+	Name    string    `json:"name"`
+	Hash    string    `json:"hash"` //内容的 SHA-256, 十六进制小写
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// ContentName URL 中使用的内容名称: 哈希+保存时的扩展名, 例: ab12...ef.png
+func (r ContentRef) ContentName() string {
+	return r.Hash + safeExt(r.Name)
+}
+
+// RefIndex ContentStorage 的引用索引, 记录逻辑名称指向的内容, 以及每个内容被引用的次数
+type RefIndex interface {
+	Load(name string) (ContentRef, bool, error)
+	// Store 保存引用, 名称已存在时返回错误
+	Store(ref ContentRef) error
+	// Delete 删除引用, 返回内容剩余的引用次数
+	Delete(name string) (ContentRef, int, error)
+	// Refs 内容被引用的次数
+	Refs(hash string) (int, error)
+	// ContentRefs 内容名称(哈希+扩展名)被引用的次数, 见 ContentRef.ContentName
+	ContentRefs(contentName string) (int, error)
+	// List 名称以 prefix 开头的所有引用, 按名称排序
+	List(prefix string) ([]ContentRef, error)
+}
+
+var _ RefIndex = &MemoryRefIndex{}
+var _ RefIndex = &FileRefIndex{}
+
+// MemoryRefIndex 保存在内存中的引用索引, 重启后丢失, 适合测试或者由外部持久化
+type MemoryRefIndex struct {
+	lock     sync.RWMutex
+	refs     map[string]ContentRef //name => ref
+	counts   map[string]int        //hash => 引用次数
+	contents map[string]int        //哈希+扩展名 => 引用次数
+}
+
+func NewMemoryRefIndex() *MemoryRefIndex {
+	return &MemoryRefIndex{refs: make(map[string]ContentRef), counts: make(map[string]int), contents: make(map[string]int)}
+}
+
+func (m *MemoryRefIndex) Load(name string) (ContentRef, bool, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var ref, ok = m.refs[name]
+	return ref, ok, nil
+}
+
+func (m *MemoryRefIndex) Store(ref ContentRef) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.store(ref)
+}
+
+func (m *MemoryRefIndex) store(ref ContentRef) error {
+	if m.refs == nil {
+		m.refs, m.counts, m.contents = make(map[string]ContentRef), make(map[string]int), make(map[string]int)
+	}
+
+	if _, ok := m.refs[ref.Name]; ok {
+		return &os.PathError{Op: "store", Path: ref.Name, Err: os.ErrExist}
+	}
+
+	m.refs[ref.Name] = ref
+	m.counts[ref.Hash]++
+	m.contents[ref.ContentName()]++
+	return nil
+}
+
+func (m *MemoryRefIndex) Delete(name string) (ContentRef, int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.delete(name)
+}
+
+func (m *MemoryRefIndex) delete(name string) (ContentRef, int, error) {
+	var ref, ok = m.refs[name]
+	if !ok {
+		return ContentRef{}, 0, &os.PathError{Op: "delete", Path: name, Err: os.ErrNotExist}
+	}
+
+	delete(m.refs, name)
+	if m.counts[ref.Hash]--; m.counts[ref.Hash] <= 0 {
+		delete(m.counts, ref.Hash)
+	}
+	if m.contents[ref.ContentName()]--; m.contents[ref.ContentName()] <= 0 {
+		delete(m.contents, ref.ContentName())
+	}
+
+	return ref, m.counts[ref.Hash], nil
+}
+
+func (m *MemoryRefIndex) Refs(hash string) (int, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.counts[hash], nil
+}
+
+func (m *MemoryRefIndex) ContentRefs(contentName string) (int, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.contents[contentName], nil
+}
+
+func (m *MemoryRefIndex) List(prefix string) ([]ContentRef, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var refs = make([]ContentRef, 0)
+	for name, ref := range m.refs {
+		if strings.HasPrefix(name, prefix) {
+			refs = append(refs, ref)
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Name < refs[j].Name
+	})
+
+	return refs, nil
+}
+
+// FileRefIndex 保存在json文件中的引用索引, 每次修改都会原子地重写整个文件,
+// Store 和 Delete 的开销是 O(n), n 为引用总数, 引用很多或写入频繁时应该使用数据库实现 RefIndex
+type FileRefIndex struct {
+	MemoryRefIndex
+	path string
+}
+
+// NewFileRefIndex 加载索引文件, 文件不存在时为空索引
+func NewFileRefIndex(filePath string) (*FileRefIndex, error) {
+	var index = &FileRefIndex{path: filePath}
+
+	var data, err = os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []ContentRef
+	if err = json.Unmarshal(data, &refs); err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		if err = index.store(ref); err != nil {
+			return nil, err
+		}
+	}
+
+	return index, nil
+}
+
+func (f *FileRefIndex) Store(ref ContentRef) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.store(ref); err != nil {
+		return err
+	}
+
+	if err := f.flush(); err != nil {
+		_, _, _ = f.delete(ref.Name)
+		return err
+	}
+
+	return nil
+}
+
+func (f *FileRefIndex) Delete(name string) (ContentRef, int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var ref, refs, err = f.delete(name)
+	if err != nil {
+		return ref, refs, err
+	}
+
+	if err = f.flush(); err != nil {
+		_ = f.store(ref)
+		return ContentRef{}, 0, err
+	}
+
+	return ref, refs, nil
+}
+
+// flush 序列化所有引用写入同目录的临时文件, 再重命名覆盖索引文件,
+// 写入失败不会损坏原索引, 读取方也不会看到写了一半的文件; 每次调用都是 O(n)
+func (f *FileRefIndex) flush() error {
+	var refs = make([]ContentRef, 0, len(f.refs))
+	for _, ref := range f.refs {
+		refs = append(refs, ref)
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Name < refs[j].Name
+	})
+
+	var data, err = json.MarshalIndent(refs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	var dir = filepath.Dir(f.path)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	var out *os.File
+	if out, err = os.CreateTemp(dir, tempPrefix+"*"); err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+
+	if _, err = out.Write(data); err == nil {
+		err = out.Sync()
+	}
+
+	if err01 := out.Close(); err == nil {
+		err = err01
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(out.Name(), f.path)
+}
@@ -1,11 +1,16 @@
 package core
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"gin-core/core/color"
 	"io"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // Render 公共写入响应流的方法
@@ -29,6 +34,148 @@ func (j JsonRender) Render(w http.ResponseWriter, v any) error {
 	return j.Serializer.Encode(w, v)
 }
 
+// #---------------------------------------------------
+// JsonpRender 输出 callback(json); 回调函数名必须是合法的js标识符
+type JsonpRender struct {
+	Callback   string
+	Serializer color.Serializer
+}
+
+// ErrInvalidCallback jsonp 回调函数名不合法
+var ErrInvalidCallback = errors.New("invalid jsonp callback")
+
+// jsonpCallbackRegexp 允许 a.b.c 形式的回调函数名
+var jsonpCallbackRegexp = regexp.MustCompile(`^[a-zA-Z_$][0-9a-zA-Z_$]*(?:\.[a-zA-Z_$][0-9a-zA-Z_$]*)*$`)
+
+func (j JsonpRender) Render(w http.ResponseWriter, v any) error {
+	if len(j.Callback) == 0 {
+		return JsonRender{Serializer: j.Serializer}.Render(w, v)
+	}
+
+	if len(j.Callback) > 128 || !jsonpCallbackRegexp.MatchString(j.Callback) {
+		return ErrInvalidCallback
+	}
+
+	writeContentType(w, "application/javascript;charset=utf-8")
+	//开头的注释可以防止 Rosetta Flash 之类的攻击
+	if _, err := io.WriteString(w, "/**/"+j.Callback+"("); err != nil {
+		return err
+	}
+
+	if err := j.Serializer.Encode(w, v); err != nil {
+		return err
+	}
+
+	var _, err = io.WriteString(w, ");")
+
+	return err
+}
+
+// #---------------------------------------------------
+// SecureJsonRender 输出前加上防劫持的前缀, 客户端需要去掉前缀后再解析
+type SecureJsonRender struct {
+	Prefix     string
+	Serializer color.Serializer
+}
+
+// DefaultSecureJSONPrefix 默认的防劫持前缀
+const DefaultSecureJSONPrefix = "while(1);"
+
+func (s SecureJsonRender) Render(w http.ResponseWriter, v any) error {
+	if len(s.Prefix) == 0 {
+		s.Prefix = DefaultSecureJSONPrefix
+	}
+
+	writeContentType(w, "application/json;charset=utf-8")
+	if _, err := io.WriteString(w, s.Prefix); err != nil {
+		return err
+	}
+
+	return s.Serializer.Encode(w, v)
+}
+
+// #---------------------------------------------------
+// PureJsonRender 不转义 <, >, & 等html字符
+type PureJsonRender struct {
+}
+
+func (p PureJsonRender) Render(w http.ResponseWriter, v any) error {
+	writeContentType(w, "application/json;charset=utf-8")
+
+	var encoder = json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(v)
+}
+
+// #---------------------------------------------------
+// IndentedJsonRender 格式化输出, 方便调试
+type IndentedJsonRender struct {
+	Indent string
+}
+
+func (i IndentedJsonRender) Render(w http.ResponseWriter, v any) error {
+	if len(i.Indent) == 0 {
+		i.Indent = "    "
+	}
+
+	writeContentType(w, "application/json;charset=utf-8")
+
+	var encoder = json.NewEncoder(w)
+	encoder.SetIndent("", i.Indent)
+
+	return encoder.Encode(v)
+}
+
+// #---------------------------------------------------
+// JsonStreamRender 逐个编码元素输出json数组, 不需要把所有数据加载到内存中
+type JsonStreamRender struct {
+	Serializer color.Serializer
+	Next       func() (any, bool) //返回 false 时结束
+	FlushEvery int                //每输出多少个元素刷新一次, 默认100
+}
+
+func (j JsonStreamRender) Render(w http.ResponseWriter, v any) error {
+	if j.FlushEvery <= 0 {
+		j.FlushEvery = 100
+	}
+
+	writeContentType(w, "application/json;charset=utf-8")
+
+	var flusher, _ = w.(http.Flusher)
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	for count := 0; ; count++ {
+		var item, ok = j.Next()
+		if !ok {
+			break
+		}
+
+		if count > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+
+		if err := j.Serializer.Encode(w, item); err != nil {
+			return err
+		}
+
+		if flusher != nil && (count+1)%j.FlushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+
+	var _, err = io.WriteString(w, "]")
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	return err
+}
+
 // #---------------------------------------------------
 type FileAttachmentRender struct {
 	FileName string
@@ -36,8 +183,53 @@ type FileAttachmentRender struct {
 	Request  *http.Request
 }
 
+// setAttachment 设置下载文件名, filename 只能是 ASCII, 非ASCII字符替换为 _,
+// 原始文件名使用 RFC 6266 的 filename* 参数
+func setAttachment(w http.ResponseWriter, filename string) {
+	var fallback strings.Builder
+	var ascii = true
+	for _, r := range filename {
+		switch {
+		case r > unicode.MaxASCII || unicode.IsControl(r):
+			ascii = false
+			fallback.WriteByte('_')
+		case r == '"' || r == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(r)
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+
+	var disposition = "attachment; filename=\"" + fallback.String() + "\""
+	if !ascii {
+		disposition += "; filename*=UTF-8''" + encodeExtValue(filename)
+	}
+
+	w.Header().Set("Content-Disposition", disposition)
+}
+
+// encodeExtValue RFC 5987 ext-value 编码, attr-char 以外的字节都使用百分号编码
+func encodeExtValue(value string) string {
+	const hex = "0123456789ABCDEF"
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		var c = value[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			builder.WriteByte(c)
+			continue
+		}
+
+		builder.WriteByte('%')
+		builder.WriteByte(hex[c>>4])
+		builder.WriteByte(hex[c&15])
+	}
+
+	return builder.String()
+}
+
 func (f FileAttachmentRender) Render(w http.ResponseWriter, v any) error {
-	w.Header().Set("Content-Disposition", "attachment; filename=\""+f.FileName+"\"")
+	setAttachment(w, f.FileName)
 
 	http.ServeFile(w, f.Request, f.FilePath)
 	return nil
@@ -102,3 +294,71 @@ func (c ContentRender) Render(w http.ResponseWriter, v any) error {
 
 	return nil
 }
+
+// #---------------------------------------------------
+type YamlRender struct {
+	Serializer color.Serializer
+}
+
+func (y YamlRender) Render(w http.ResponseWriter, v any) error {
+	writeContentType(w, "application/yaml;charset=utf-8")
+
+	return y.Serializer.Encode(w, v)
+}
+
+// #---------------------------------------------------
+type TomlRender struct {
+	Serializer color.Serializer
+}
+
+func (t TomlRender) Render(w http.ResponseWriter, v any) error {
+	writeContentType(w, "application/toml;charset=utf-8")
+
+	return t.Serializer.Encode(w, v)
+}
+
+// #---------------------------------------------------
+type MsgPackRender struct {
+	Serializer color.Serializer
+}
+
+func (m MsgPackRender) Render(w http.ResponseWriter, v any) error {
+	writeContentType(w, "application/msgpack")
+
+	return m.Serializer.Encode(w, v)
+}
+
+// #---------------------------------------------------
+type CborRender struct {
+	Serializer color.Serializer
+}
+
+func (c CborRender) Render(w http.ResponseWriter, v any) error {
+	writeContentType(w, "application/cbor")
+
+	return c.Serializer.Encode(w, v)
+}
+
+// #---------------------------------------------------
+type ProtoBufRender struct {
+	Serializer color.Serializer
+}
+
+func (p ProtoBufRender) Render(w http.ResponseWriter, v any) error {
+	writeContentType(w, "application/x-protobuf")
+
+	return p.Serializer.Encode(w, v)
+}
+
+// #---------------------------------------------------
+// SerializerRender 使用任意序列化器输出, 用于自定义的媒体类型
+type SerializerRender struct {
+	ContentType string
+	Serializer  color.Serializer
+}
+
+func (s SerializerRender) Render(w http.ResponseWriter, v any) error {
+	writeContentType(w, s.ContentType)
+
+	return s.Serializer.Encode(w, v)
+}
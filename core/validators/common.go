@@ -11,11 +11,29 @@ const (
 	phoneRegx = "^(?:\\+?86)?1(?:3\\d{3}|5[^4\\D]\\d{2}|8\\d{3}|7(?:[35678]\\d{2}|4(?:0\\d|1[0-2]|9\\d))|9[189]\\d{2}|66\\d{2})\\d{6}$"
 	//url
 	urlRegx = "^(?:(?:https?|ftp)://)(?:\\S+(?::\\S*)?@|\\d{1,3}(?:\\.\\d{1,3}){3}|(?:(?:[a-z\\d\\x{00a1}-\\x{ffff}]+-?)*[a-z\\d\\x{00a1}-\\x{ffff}]+)(?:\\.(?:[a-z\\d\\x{00a1}-\\x{ffff}]+-?)*[a-z\\d\\x{00a1}-\\x{ffff}]+)*(?:\\.[a-z\\x{00a1}-\\x{ffff}]{2,6}))(?::\\d+)?(?:[^\\s]*)?$"
+	//主机名 RFC 1123
+	hostnameRegx = "^[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$"
+	//完整域名, 顶级域名不能是纯数字
+	fqdnRegx = "^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\\.)+[a-zA-Z](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\\.?$"
+	//十六进制颜色 #fff #ffffff #ffffffff
+	hexColorRegx = "^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$"
+	//语义化版本 https://semver.org
+	semverRegx = "^v?(?:0|[1-9]\\d*)\\.(?:0|[1-9]\\d*)\\.(?:0|[1-9]\\d*)(?:-(?:(?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\\.(?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\\+[0-9a-zA-Z-]+(?:\\.[0-9a-zA-Z-]+)*)?$"
+	//中国居民身份证号
+	idCardRegx = "^[1-9]\\d{5}(?:18|19|20)\\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\\d|3[01])\\d{3}[\\dXx]$"
+	//银行卡号
+	bankCardRegx = "^[1-9]\\d{15,18}$"
 )
 
 var (
-	emailRegex  = regexp.MustCompile(emailRegx)
-	uuidRegexp  = regexp.MustCompile(uuidRegx)
-	phoneRegexp = regexp.MustCompile(phoneRegx)
-	urlRegexp   = regexp.MustCompile(urlRegx)
+	emailRegex     = regexp.MustCompile(emailRegx)
+	uuidRegexp     = regexp.MustCompile(uuidRegx)
+	phoneRegexp    = regexp.MustCompile(phoneRegx)
+	urlRegexp      = regexp.MustCompile(urlRegx)
+	hostnameRegexp = regexp.MustCompile(hostnameRegx)
+	fqdnRegexp     = regexp.MustCompile(fqdnRegx)
+	hexColorRegexp = regexp.MustCompile(hexColorRegx)
+	semverRegexp   = regexp.MustCompile(semverRegx)
+	idCardRegexp   = regexp.MustCompile(idCardRegx)
+	bankCardRegexp = regexp.MustCompile(bankCardRegx)
 )
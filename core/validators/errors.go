@@ -1,11 +1,16 @@
 package validators
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 type ValidationError struct {
 	error
 	FieldName string `json:"field_name"`
 	Rule      string `json:"rule"`
+	Message   string `json:"message"`
+	Path      string `json:"path"` //字段路径, 优先使用json tag, 例: email
 }
 
 // Unwrap 获取原始错误
@@ -18,20 +23,49 @@ func NewValidationError(err error, fieldName string, rule string) *ValidationErr
 		error:     err,
 		FieldName: fieldName,
 		Rule:      rule,
+		Message:   err.Error(),
+		Path:      fieldName,
+	}
+}
+
+// ValidationErrors 所有验证失败的字段, 每个字段只记录第一条失败的规则
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	var messages = make([]string, len(e))
+	for i, err := range e {
+		if len(err.Path) == 0 { //结构体级别的错误没有字段路径
+			messages[i] = err.Message
+			continue
+		}
+
+		messages[i] = err.Path + ": " + err.Message
 	}
+
+	return strings.Join(messages, "; ")
+}
+
+// As 让 errors.As(err, &*ValidationError) 能取到第一个错误
+func (e ValidationErrors) As(target any) bool {
+	if ptr, ok := target.(**ValidationError); ok && len(e) > 0 {
+		*ptr = e[0]
+		return true
+	}
+
+	return false
 }
 
 var (
-	// 备注必填
-	messageParamRequiredError = errors.New("param error, param `message` is required")
 	// 值必填
 	valueParamRequiredError = errors.New("param error, param `value` is required")
+	// 验证的值是 nil
+	invalidValueError = errors.New("validate: value is nil or invalid")
 	// 其他错误
 	unsupportedError = errors.New("unsupported error")
 )
 
 func IsValidationError(err error) bool {
-	var _, ok = err.(ValidationError)
+	var validationErr *ValidationError
 
-	return ok
+	return errors.As(err, &validationErr)
 }
@@ -0,0 +1,223 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" //注册图片格式, image.DecodeConfig 使用
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// sniffLen http.DetectContentType 最多读取的字节数
+const sniffLen = 512
+
+// fileHeaders 获取上传文件字段, 支持 *multipart.FileHeader 和 []*multipart.FileHeader
+func fileHeaders(field reflect.Value) ([]*multipart.FileHeader, error) {
+	switch field.Type() {
+	case fileHeaderType:
+		if field.IsNil() {
+			return nil, nil
+		}
+
+		return []*multipart.FileHeader{field.Interface().(*multipart.FileHeader)}, nil
+	case fileHeadersType:
+		return field.Interface().([]*multipart.FileHeader), nil
+	default:
+		return nil, errors.New("file rules only support *multipart.FileHeader and []*multipart.FileHeader type")
+	}
+}
+
+// parseSize 解析文件大小, 例: 1024, 512KB, 10MB, 1GB
+func parseSize(text string) (int64, error) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	var units = []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}}
+	for _, unit := range units {
+		if strings.HasSuffix(text, unit.suffix) {
+			var n, err = strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(text, unit.suffix)), 64)
+			if err != nil {
+				return 0, fmt.Errorf("%s is not a valid size", text)
+			}
+
+			return int64(n * float64(unit.size)), nil
+		}
+	}
+
+	return strconv.ParseInt(text, 10, 64)
+}
+
+// eachFile 对每个上传的文件执行检查, 没有上传文件时不验证(是否必填由 required 决定)
+func eachFile(valueOf reflect.Value, index int, param Param, check func(file *multipart.FileHeader) (bool, error)) error {
+	var files, err = fileHeaders(valueOf.Field(index))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		var ok, err = check(file)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return param.Fail()
+		}
+	}
+
+	return nil
+}
+
+// MaxSize 每个文件的大小不能超过指定值, 例: max_size(v=2MB)
+func MaxSize(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var size, err = parseSize(param.Value)
+	if err != nil {
+		return err
+	}
+
+	return eachFile(valueOf, index, param, func(file *multipart.FileHeader) (bool, error) {
+		return file.Size <= size, nil
+	})
+}
+
+// MinSize 每个文件的大小不能小于指定值
+func MinSize(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var size, err = parseSize(param.Value)
+	if err != nil {
+		return err
+	}
+
+	return eachFile(valueOf, index, param, func(file *multipart.FileHeader) (bool, error) {
+		return file.Size >= size, nil
+	})
+}
+
+// sniffContentType 读取文件开头的字节检测文件类型, 不信任客户端的 Content-Type
+func sniffContentType(file *multipart.FileHeader) (string, error) {
+	var f, err = file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var buf = make([]byte, sniffLen)
+	var n int
+	if n, err = io.ReadFull(f, buf); err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	var contentType, _, _ = strings.Cut(http.DetectContentType(buf[:n]), ";")
+	return strings.TrimSpace(contentType), nil
+}
+
+// Mimes 文件内容的类型必须是指定类型之一, 多个类型使用空格分隔, 例: mimes(v=image/png image/jpeg), mimes(v=image/*)
+func Mimes(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	if len(param.Value) == 0 {
+		return valueParamRequiredError
+	}
+
+	var allowed = strings.Fields(strings.ToLower(param.Value))
+	return eachFile(valueOf, index, param, func(file *multipart.FileHeader) (bool, error) {
+		var contentType, err = sniffContentType(file)
+		if err != nil {
+			return false, err
+		}
+
+		for _, mime := range allowed {
+			if mime == contentType || (strings.HasSuffix(mime, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(mime, "*"))) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+}
+
+// Ext 文件扩展名必须是指定扩展名之一, 不区分大小写, 例: ext(v=jpg png .gif)
+func Ext(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	if len(param.Value) == 0 {
+		return valueParamRequiredError
+	}
+
+	var allowed = strings.Fields(strings.ToLower(param.Value))
+	return eachFile(valueOf, index, param, func(file *multipart.FileHeader) (bool, error) {
+		var ext = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+		for _, item := range allowed {
+			if strings.TrimPrefix(item, ".") == ext {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+}
+
+// imageConfig 读取图片的宽高, 不是支持的图片格式时验证失败
+func imageConfig(file *multipart.FileHeader) (image.Config, bool, error) {
+	var f, err = file.Open()
+	if err != nil {
+		return image.Config{}, false, err
+	}
+	defer f.Close()
+
+	var config image.Config
+	if config, _, err = image.DecodeConfig(f); err != nil {
+		return image.Config{}, false, nil
+	}
+
+	return config, true, nil
+}
+
+// imageDimension 图片宽高限制
+func imageDimension(check func(config image.Config, except int) bool) ValidatorFunc {
+	return func(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+		var except, err = strconv.Atoi(param.Value)
+		if err != nil {
+			return err
+		}
+
+		return eachFile(valueOf, index, param, func(file *multipart.FileHeader) (bool, error) {
+			var config, ok, err = imageConfig(file)
+			if err != nil || !ok {
+				return false, err
+			}
+
+			return check(config, except), nil
+		})
+	}
+}
+
+// fileCount 上传文件的数量限制
+func fileCount(check func(count, except int) bool) ValidatorFunc {
+	return func(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+		var except, err = strconv.Atoi(param.Value)
+		if err != nil {
+			return err
+		}
+
+		var files []*multipart.FileHeader
+		if files, err = fileHeaders(valueOf.Field(index)); err != nil {
+			return err
+		}
+
+		if !check(len(files), except) {
+			return param.Fail()
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,217 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ValidatorLibrary 验证器管理器, 每个 BluePrint 可以使用不同的规则
+type ValidatorLibrary struct {
+	FailFast bool //遇到第一个错误就返回, 默认收集所有字段的错误
+
+	lock     sync.RWMutex
+	funcs    map[string]ValidatorFunc
+	ctxFuncs map[string]ContextValidatorFunc
+	messages map[string]map[string]string //locale => rule => 消息模板
+	schemas  map[string]SchemaFunc        //rule => JSON Schema 转换函数
+	plans    *sync.Map                    //reflect.Type => *structPlan
+}
+
+// validatorLibrary 全局默认的验证器管理器, validators.Default 使用
+var validatorLibrary = NewLibrary()
+
+// NewLibrary 创建包含所有内置验证器的管理器
+func NewLibrary() *ValidatorLibrary {
+	return &ValidatorLibrary{
+		funcs:    builtinValidators(),
+		ctxFuncs: make(map[string]ContextValidatorFunc, 0),
+		messages: builtinMessages(),
+		schemas:  builtinSchemas(),
+		plans:    &sync.Map{},
+	}
+}
+
+// DefaultLibrary 全局默认的验证器管理器
+func DefaultLibrary() *ValidatorLibrary {
+	return validatorLibrary
+}
+
+// Clone 复制一份管理器, 修改副本不影响原来的规则
+func (validate *ValidatorLibrary) Clone() *ValidatorLibrary {
+	validate.lock.RLock()
+	defer validate.lock.RUnlock()
+
+	var library = &ValidatorLibrary{
+		FailFast: validate.FailFast,
+		funcs:    make(map[string]ValidatorFunc, len(validate.funcs)),
+		ctxFuncs: make(map[string]ContextValidatorFunc, len(validate.ctxFuncs)),
+		messages: cloneMessages(validate.messages),
+		schemas:  make(map[string]SchemaFunc, len(validate.schemas)),
+		plans:    &sync.Map{},
+	}
+	for key, fn := range validate.funcs {
+		library.funcs[key] = fn
+	}
+
+	for key, fn := range validate.ctxFuncs {
+		library.ctxFuncs[key] = fn
+	}
+
+	for key, fn := range validate.schemas {
+		library.schemas[key] = fn
+	}
+
+	return library
+}
+
+// Register 注册验证器, 已经存在时返回错误
+func (validate *ValidatorLibrary) Register(key string, val ValidatorFunc) error {
+	validate.lock.Lock()
+	defer validate.lock.Unlock()
+
+	if validate.exists(key) {
+		return fmt.Errorf("key %s has already exits", key)
+	}
+
+	validate.funcs[key] = val
+	validate.plans = &sync.Map{}
+	return nil
+}
+
+// RegisterContext 注册需要上下文的验证器, 已经存在时返回错误
+func (validate *ValidatorLibrary) RegisterContext(key string, val ContextValidatorFunc) error {
+	validate.lock.Lock()
+	defer validate.lock.Unlock()
+
+	if validate.exists(key) {
+		return fmt.Errorf("key %s has already exits", key)
+	}
+
+	validate.ctxFuncs[key] = val
+	validate.plans = &sync.Map{}
+	return nil
+}
+
+// Override 注册或覆盖验证器
+func (validate *ValidatorLibrary) Override(key string, val ValidatorFunc) {
+	validate.lock.Lock()
+	defer validate.lock.Unlock()
+
+	delete(validate.ctxFuncs, key)
+	validate.funcs[key] = val
+	validate.plans = &sync.Map{}
+}
+
+// OverrideContext 注册或覆盖需要上下文的验证器
+func (validate *ValidatorLibrary) OverrideContext(key string, val ContextValidatorFunc) {
+	validate.lock.Lock()
+	defer validate.lock.Unlock()
+
+	delete(validate.funcs, key)
+	validate.ctxFuncs[key] = val
+	validate.plans = &sync.Map{}
+}
+
+// Unregister 删除验证器, 使用该规则的结构体再次验证时会返回错误
+func (validate *ValidatorLibrary) Unregister(key string) {
+	validate.lock.Lock()
+	defer validate.lock.Unlock()
+
+	delete(validate.funcs, key)
+	delete(validate.ctxFuncs, key)
+	validate.plans = &sync.Map{}
+}
+
+// exists 调用前需要加锁
+func (validate *ValidatorLibrary) exists(key string) bool {
+	var _, ok = validate.funcs[key]
+	var _, ok01 = validate.ctxFuncs[key]
+
+	return ok || ok01
+}
+
+// lookup 获取验证器
+func (validate *ValidatorLibrary) lookup(key string) (ValidatorFunc, ContextValidatorFunc, bool) {
+	validate.lock.RLock()
+	defer validate.lock.RUnlock()
+
+	var fn, ok = validate.funcs[key]
+	var ctxFn, ok01 = validate.ctxFuncs[key]
+
+	return fn, ctxFn, ok || ok01
+}
+
+// loadPlans 当前的验证计划缓存
+func (validate *ValidatorLibrary) loadPlans() *sync.Map {
+	validate.lock.RLock()
+	defer validate.lock.RUnlock()
+
+	return validate.plans
+}
+
+// Validate 验证所有字段, 返回 ValidationErrors
+func (validate *ValidatorLibrary) Validate(value any) error {
+	return validate.validate(context.Background(), value, validate.FailFast)
+}
+
+// ValidateFailFast 遇到第一个验证失败的规则就返回 *ValidationError
+func (validate *ValidatorLibrary) ValidateFailFast(value any) error {
+	return validate.validate(context.Background(), value, true)
+}
+
+// ValidateContext 验证所有字段, ctx 会传给需要上下文的验证器和结构体
+func (validate *ValidatorLibrary) ValidateContext(ctx context.Context, value any) error {
+	return validate.validate(ctx, value, validate.FailFast)
+}
+
+func (validate *ValidatorLibrary) validate(ctx context.Context, value any, failFast bool) error {
+	var v = reflect.ValueOf(value)
+	for reflect.Ptr == v.Kind() || reflect.Interface == v.Kind() { //解引用(去指针化)
+		if v.IsNil() {
+			return invalidValueError
+		}
+
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return invalidValueError
+	}
+
+	var w = &walker{library: validate, ctx: ctx, locales: LocaleFromContext(ctx), failFast: failFast}
+	var err error
+	switch v.Kind() {
+	case reflect.Struct:
+		err = w.validateStruct(v, "")
+	case reflect.Slice, reflect.Array, reflect.Map:
+		//顶层的切片/map, 例: json 数组请求体, 验证每个结构体元素
+		if isNestedStruct(v.Type().Elem()) {
+			err = w.validateElems(v, fieldRules{nested: true}, "")
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(w.errs) > 0 {
+		return w.errs
+	}
+
+	return nil
+}
+
+// RegisterValidator 注册验证器到全局默认的管理器
+func RegisterValidator(key string, val ValidatorFunc) error {
+	return validatorLibrary.Register(key, val)
+}
+
+// RegisterContextValidator 注册需要上下文的验证器到全局默认的管理器, 例: 检测用户名是否已存在
+func RegisterContextValidator(key string, val ContextValidatorFunc) error {
+	return validatorLibrary.RegisterContext(key, val)
+}
+
+var _ Validator = (*ValidatorLibrary)(nil)
+var _ ContextValidator = (*ValidatorLibrary)(nil)
@@ -0,0 +1,337 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DefaultLocale 找不到客户端语言的消息时使用
+const DefaultLocale = "en"
+
+// defaultMessageKey 规则没有对应的消息时使用
+const defaultMessageKey = "default"
+
+// ruleError 规则验证失败, message 为空时从消息目录中获取
+type ruleError struct {
+	message string
+}
+
+func (e *ruleError) Error() string {
+	if len(e.message) == 0 {
+		return "validation failed"
+	}
+
+	return e.message
+}
+
+// Fail 规则验证失败时返回, 没有设置 m= 时使用消息目录中的提示,
+// 消息中可以使用 {field} {value} {param} 占位符
+func (p Param) Fail() error {
+	return &ruleError{message: p.Message}
+}
+
+// messageData 渲染消息模板的数据
+type messageData struct {
+	field string //label tag, 没有时为json字段名
+	value reflect.Value
+	param Param
+}
+
+// localeKey 上下文中保存客户端语言
+type localeKey struct{}
+
+// WithLocale 设置验证消息的语言, 按优先级排列, 例: zh-CN, en
+func WithLocale(ctx context.Context, locales ...string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locales)
+}
+
+// LocaleFromContext 获取验证消息的语言
+func LocaleFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+
+	var locales, _ = ctx.Value(localeKey{}).([]string)
+	return locales
+}
+
+// RegisterMessages 添加或覆盖全局默认管理器的消息模板
+func RegisterMessages(locale string, messages map[string]string) {
+	validatorLibrary.RegisterMessages(locale, messages)
+}
+
+// RegisterMessages 添加或覆盖某种语言的消息模板, key 为规则名
+func (validate *ValidatorLibrary) RegisterMessages(locale string, messages map[string]string) {
+	validate.lock.Lock()
+	defer validate.lock.Unlock()
+
+	locale = strings.ToLower(locale)
+	var catalog, ok = validate.messages[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		validate.messages[locale] = catalog
+	}
+
+	for rule, message := range messages {
+		catalog[rule] = message
+	}
+}
+
+// Message 获取规则的消息模板, locales 按优先级排列, 都没有时使用 DefaultLocale
+func (validate *ValidatorLibrary) Message(rule string, locales ...string) string {
+	validate.lock.RLock()
+	defer validate.lock.RUnlock()
+
+	var candidates = make([]string, 0, len(locales)*2+1)
+	for _, locale := range locales {
+		locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+		candidates = append(candidates, locale)
+		if base, _, ok := strings.Cut(locale, "-"); ok {
+			candidates = append(candidates, base)
+		}
+	}
+
+	candidates = append(candidates, DefaultLocale)
+	for _, locale := range candidates {
+		var catalog, ok = validate.messages[locale]
+		if !ok {
+			continue
+		}
+
+		if message, exist := catalog[rule]; exist {
+			return message
+		}
+
+		if message, exist := catalog[defaultMessageKey]; exist {
+			return message
+		}
+	}
+
+	return "{field} is invalid"
+}
+
+// render 替换消息模板中的占位符
+func (validate *ValidatorLibrary) render(message, rule string, locales []string, data messageData) string {
+	if len(message) == 0 {
+		message = validate.Message(rule, locales...)
+	}
+
+	if !strings.Contains(message, "{") {
+		return message
+	}
+
+	var value = data.value
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	var text string
+	if value.IsValid() && value.CanInterface() {
+		text = fmt.Sprint(value.Interface())
+	}
+
+	var pairs = []string{
+		"{field}", data.field,
+		"{value}", text,
+		"{param}", data.param.Value,
+		"{rule}", rule,
+	}
+	for key, value := range data.param.Params {
+		pairs = append(pairs, "{"+key+"}", value)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// cloneMessages 复制消息目录
+func cloneMessages(messages map[string]map[string]string) map[string]map[string]string {
+	var result = make(map[string]map[string]string, len(messages))
+	for locale, catalog := range messages {
+		var copied = make(map[string]string, len(catalog))
+		for rule, message := range catalog {
+			copied[rule] = message
+		}
+
+		result[locale] = copied
+	}
+
+	return result
+}
+
+// builtinMessages 内置规则的英文和中文消息
+func builtinMessages() map[string]map[string]string {
+	return map[string]map[string]string{
+		"en": {
+			defaultMessageKey:   "{field} is invalid",
+			"required":          "{field} is required",
+			"max_length":        "{field} must be shorter than {param} characters",
+			"min_length":        "{field} must be longer than {param} characters",
+			"gt":                "{field} must be greater than {param}",
+			"lt":                "{field} must be less than {param}",
+			"ge":                "{field} must be greater than or equal to {param}",
+			"le":                "{field} must be less than or equal to {param}",
+			"equal":             "{field} must be equal to {param}",
+			"eq":                "{field} must be equal to {param}",
+			"equal_field":       "{field} must be equal to {param}",
+			"ef":                "{field} must be equal to {param}",
+			"method":            "{field} is invalid",
+			"regexp":            "{field} has an invalid format",
+			"email":             "{field} must be a valid email address",
+			"uuid":              "{field} must be a valid UUID",
+			"phone":             "{field} must be a valid phone number",
+			"between":           "{field} must be between {param}",
+			"not_between":       "{field} must not be between {param}",
+			"not_equal":         "{field} must not be equal to {param}",
+			"ne":                "{field} must not be equal to {param}",
+			"not_equal_field":   "{field} must not be equal to {param}",
+			"nef":               "{field} must not be equal to {param}",
+			"url":               "{field} must be a valid URL",
+			"contains":          "{field} must contain {param}",
+			"not_contains":      "{field} must not contain {param}",
+			"date_format":       "{field} must match the date format {param}",
+			"great_than_field":  "{field} must be greater than {param}",
+			"gtf":               "{field} must be greater than {param}",
+			"lower_than_field":  "{field} must be less than {param}",
+			"ltf":               "{field} must be less than {param}",
+			"great_equal_field": "{field} must be greater than or equal to {param}",
+			"gef":               "{field} must be greater than or equal to {param}",
+			"lower_equal_field": "{field} must be less than or equal to {param}",
+			"lef":               "{field} must be less than or equal to {param}",
+			"round":             "{field} must have at most {param} decimal places",
+			"required_if":       "{field} is required when {param}",
+			"required_unless":   "{field} is required unless {param}",
+			"required_with":     "{field} is required when {param} is present",
+			"required_without":  "{field} is required when {param} is missing",
+			"excluded_if":       "{field} must be empty when {param}",
+			"len":               "{field} must be exactly {param} in length",
+			"ip":                "{field} must be a valid IP address",
+			"ipv4":              "{field} must be a valid IPv4 address",
+			"ipv6":              "{field} must be a valid IPv6 address",
+			"cidr":              "{field} must be a valid CIDR notation",
+			"hostname":          "{field} must be a valid hostname",
+			"fqdn":              "{field} must be a fully qualified domain name",
+			"mac":               "{field} must be a valid MAC address",
+			"port":              "{field} must be a valid port number",
+			"json":              "{field} must be valid JSON",
+			"base64":            "{field} must be a valid Base64 string",
+			"base64url":         "{field} must be a valid URL-safe Base64 string",
+			"hex_color":         "{field} must be a valid hex color",
+			"semver":            "{field} must be a valid semantic version",
+			"credit_card":       "{field} must be a valid credit card number",
+			"isbn":              "{field} must be a valid ISBN",
+			"isbn10":            "{field} must be a valid ISBN-10",
+			"isbn13":            "{field} must be a valid ISBN-13",
+			"id_card":           "{field} must be a valid resident ID number",
+			"bank_card":         "{field} must be a valid bank card number",
+			"latitude":          "{field} must be a valid latitude",
+			"longitude":         "{field} must be a valid longitude",
+			"oneof":             "{field} must be one of [{param}]",
+			"unique":            "{field} must contain unique values",
+			"alpha":             "{field} can only contain letters",
+			"alphanumeric":      "{field} can only contain letters and numbers",
+			"ascii":             "{field} can only contain ASCII characters",
+			"printable":         "{field} can only contain printable characters",
+			"before":            "{field} must be before {param}",
+			"after":             "{field} must be after {param}",
+			"min_age":           "{field} must be at least {param} years old",
+			"max_age":           "{field} must be at most {param} years old",
+			"max_size":          "{field} must not be larger than {param}",
+			"min_size":          "{field} must not be smaller than {param}",
+			"mimes":             "{field} must be a file of type: {param}",
+			"ext":               "{field} must be a file with extension: {param}",
+			"max_width":         "{field} must not be wider than {param} pixels",
+			"max_height":        "{field} must not be taller than {param} pixels",
+			"min_width":         "{field} must be at least {param} pixels wide",
+			"min_height":        "{field} must be at least {param} pixels tall",
+			"max_files":         "{field} must not have more than {param} files",
+			"min_files":         "{field} must have at least {param} files",
+		},
+		"zh": {
+			defaultMessageKey:   "{field}不合法",
+			"required":          "{field}不能为空",
+			"max_length":        "{field}长度必须小于{param}个字符",
+			"min_length":        "{field}长度必须大于{param}个字符",
+			"gt":                "{field}必须大于{param}",
+			"lt":                "{field}必须小于{param}",
+			"ge":                "{field}必须大于或等于{param}",
+			"le":                "{field}必须小于或等于{param}",
+			"equal":             "{field}必须等于{param}",
+			"eq":                "{field}必须等于{param}",
+			"equal_field":       "{field}必须和{param}相同",
+			"ef":                "{field}必须和{param}相同",
+			"method":            "{field}不合法",
+			"regexp":            "{field}格式不正确",
+			"email":             "{field}必须是有效的邮箱地址",
+			"uuid":              "{field}必须是有效的UUID",
+			"phone":             "{field}必须是有效的手机号码",
+			"between":           "{field}必须在{param}之间",
+			"not_between":       "{field}不能在{param}之间",
+			"not_equal":         "{field}不能等于{param}",
+			"ne":                "{field}不能等于{param}",
+			"not_equal_field":   "{field}不能和{param}相同",
+			"nef":               "{field}不能和{param}相同",
+			"url":               "{field}必须是有效的URL",
+			"contains":          "{field}必须包含{param}",
+			"not_contains":      "{field}不能包含{param}",
+			"date_format":       "{field}必须符合日期格式{param}",
+			"great_than_field":  "{field}必须大于{param}",
+			"gtf":               "{field}必须大于{param}",
+			"lower_than_field":  "{field}必须小于{param}",
+			"ltf":               "{field}必须小于{param}",
+			"great_equal_field": "{field}必须大于或等于{param}",
+			"gef":               "{field}必须大于或等于{param}",
+			"lower_equal_field": "{field}必须小于或等于{param}",
+			"lef":               "{field}必须小于或等于{param}",
+			"round":             "{field}最多保留{param}位小数",
+			"required_if":       "{param}时{field}不能为空",
+			"required_unless":   "除非{param}, 否则{field}不能为空",
+			"required_with":     "填写{param}时{field}不能为空",
+			"required_without":  "未填写{param}时{field}不能为空",
+			"excluded_if":       "{param}时{field}必须为空",
+			"len":               "{field}长度必须等于{param}",
+			"ip":                "{field}必须是有效的IP地址",
+			"ipv4":              "{field}必须是有效的IPv4地址",
+			"ipv6":              "{field}必须是有效的IPv6地址",
+			"cidr":              "{field}必须是有效的CIDR",
+			"hostname":          "{field}必须是有效的主机名",
+			"fqdn":              "{field}必须是完整的域名",
+			"mac":               "{field}必须是有效的MAC地址",
+			"port":              "{field}必须是有效的端口号",
+			"json":              "{field}必须是有效的JSON",
+			"base64":            "{field}必须是有效的Base64字符串",
+			"base64url":         "{field}必须是有效的URL安全Base64字符串",
+			"hex_color":         "{field}必须是有效的十六进制颜色",
+			"semver":            "{field}必须是有效的语义化版本号",
+			"credit_card":       "{field}必须是有效的信用卡号",
+			"isbn":              "{field}必须是有效的ISBN",
+			"isbn10":            "{field}必须是有效的ISBN-10",
+			"isbn13":            "{field}必须是有效的ISBN-13",
+			"id_card":           "{field}必须是有效的身份证号",
+			"bank_card":         "{field}必须是有效的银行卡号",
+			"latitude":          "{field}必须是有效的纬度",
+			"longitude":         "{field}必须是有效的经度",
+			"oneof":             "{field}必须是[{param}]中的一个",
+			"unique":            "{field}不能包含重复的值",
+			"alpha":             "{field}只能包含字母",
+			"alphanumeric":      "{field}只能包含字母和数字",
+			"ascii":             "{field}只能包含ASCII字符",
+			"printable":         "{field}只能包含可打印字符",
+			"before":            "{field}必须早于{param}",
+			"after":             "{field}必须晚于{param}",
+			"min_age":           "{field}对应的年龄不能小于{param}岁",
+			"max_age":           "{field}对应的年龄不能大于{param}岁",
+			"max_size":          "{field}不能大于{param}",
+			"min_size":          "{field}不能小于{param}",
+			"mimes":             "{field}的文件类型必须是{param}",
+			"ext":               "{field}的扩展名必须是{param}",
+			"max_width":         "{field}的宽度不能大于{param}像素",
+			"max_height":        "{field}的高度不能大于{param}像素",
+			"min_width":         "{field}的宽度不能小于{param}像素",
+			"min_height":        "{field}的高度不能小于{param}像素",
+			"max_files":         "{field}最多上传{param}个文件",
+			"min_files":         "{field}至少上传{param}个文件",
+		},
+	}
+}
@@ -0,0 +1,207 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rule 解析后的单条验证规则
+type rule struct {
+	name  string
+	fn    ValidatorFunc
+	ctxFn ContextValidatorFunc //需要上下文的验证器, 和 fn 二选一
+	param Param
+}
+
+// fieldRules 单个字段的所有验证规则
+type fieldRules struct {
+	index     int
+	name      string
+	path      string //json 字段名
+	label     string //错误消息中的字段名, label tag, 没有时为json字段名
+	embedded  bool   //匿名字段, 错误路径不增加层级
+	omitempty bool   //字段为空时跳过所有规则
+	rules     []rule //作用于字段本身的规则
+	dive      bool   //验证切片/数组/map的每个元素
+	elemOmit  bool   //元素为空时跳过元素的规则
+	elemRules []rule //dive 之后的规则, 作用于每个元素
+	elemType  reflect.Type
+	holder    reflect.Type //包装元素的结构体, 让 ValidatorFunc 可以验证元素
+	nested    bool         //字段(或元素)是结构体, 需要递归验证
+	promoted  bool         //匿名字段的验证方法提升到了外层结构体, 只由外层调用一次
+}
+
+// structPlan 结构体的验证计划, 第一次使用时解析并缓存
+type structPlan struct {
+	fields []fieldRules
+}
+
+const (
+	diveRule      = "dive"      //之后的规则作用于切片/数组/map的每个元素
+	omitemptyRule = "omitempty" //字段为空时不验证
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var regexpsCache sync.Map //pattern => *regexp.Regexp
+
+// compileRegexp 编译并缓存用户定义的正则表达式
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if reg, ok := regexpsCache.Load(pattern); ok {
+		return reg.(*regexp.Regexp), nil
+	}
+
+	var reg, err = regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexpsCache.Store(pattern, reg)
+	return reg, nil
+}
+
+// planResult 解析结果, tag 错误也会缓存, 同一个结构体只解析一次
+type planResult struct {
+	plan *structPlan
+	err  error
+}
+
+// plan 获取结构体的验证计划, 每个 ValidatorLibrary 单独缓存, 注册规则后缓存失效
+func (validate *ValidatorLibrary) plan(t reflect.Type) (*structPlan, error) {
+	var plans = validate.loadPlans()
+	if result, ok := plans.Load(t); ok {
+		return result.(*planResult).plan, result.(*planResult).err
+	}
+
+	var plan, err = validate.compile(t)
+	var actual, _ = plans.LoadOrStore(t, &planResult{plan: plan, err: err})
+
+	return actual.(*planResult).plan, actual.(*planResult).err
+}
+
+// compile 解析结构体所有字段的 validate tag, 结构体字段即使没有tag也会递归验证
+func (validate *ValidatorLibrary) compile(t reflect.Type) (*structPlan, error) {
+	var plan = &structPlan{}
+	var hook = hookMethod(t)
+	for i := 0; i < t.NumField(); i++ {
+		var field = t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		var rules []rule
+		var tags, ok = field.Tag.Lookup(validatorTag)
+		if ok {
+			var err error
+			if rules, err = validate.parseRules(tags); err != nil {
+				return nil, &TagError{Struct: t.String(), Field: field.Name, Tag: tags, Err: err}
+			}
+		}
+
+		var fr = fieldRules{
+			index:    i,
+			name:     field.Name,
+			path:     jsonName(field),
+			label:    field.Tag.Get(labelTag),
+			embedded: field.Anonymous,
+		}
+		if len(fr.label) == 0 {
+			fr.label = fr.path
+		}
+
+		for _, r := range rules {
+			switch {
+			case r.name == diveRule:
+				fr.dive = true
+			case r.name == omitemptyRule && fr.dive:
+				fr.elemOmit = true
+			case r.name == omitemptyRule:
+				fr.omitempty = true
+			case fr.dive:
+				fr.elemRules = append(fr.elemRules, r)
+			default:
+				fr.rules = append(fr.rules, r)
+			}
+		}
+
+		if fr.dive {
+			var kind = field.Type.Kind()
+			if kind != reflect.Slice && kind != reflect.Array && kind != reflect.Map {
+				return nil, &TagError{Struct: t.String(), Field: field.Name, Tag: tags, Err: errors.New("dive only support slice, array and map")}
+			}
+
+			fr.elemType = field.Type.Elem()
+			fr.nested = isNestedStruct(fr.elemType)
+			if len(fr.elemRules) > 0 {
+				fr.holder = reflect.StructOf([]reflect.StructField{{Name: "Value", Type: fr.elemType}})
+			}
+		} else {
+			fr.nested = isNestedStruct(field.Type)
+			fr.promoted = fr.embedded && fr.nested && len(hook) > 0 && hookMethod(field.Type) == hook
+		}
+
+		if len(fr.rules) > 0 || fr.dive || fr.nested {
+			plan.fields = append(plan.fields, fr)
+		}
+	}
+
+	return plan, nil
+}
+
+// isNestedStruct 结构体或者结构体指针, time.Time 和上传文件不需要递归
+func isNestedStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && t != timeType && t != fileHeaderType.Elem()
+}
+
+// jsonName 字段在json中的名称, 没有json tag时使用字段名
+func jsonName(field reflect.StructField) string {
+	var name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+	if len(name) == 0 || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+// parseRules 解析 validate tag 并关联验证器, 语法见 tagParser
+// required;max_length(m=姓名长度不能大于10,v=10)
+// regexp(m="编码格式错误, 例: ABC123",v=^[A-Z]{3}\d+$)
+func (validate *ValidatorLibrary) parseRules(tags string) ([]rule, error) {
+	var parsed, err = parseTag(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules = make([]rule, 0, len(parsed))
+	for _, r := range parsed {
+		if r.name == diveRule || r.name == omitemptyRule { //只是一个标记
+			rules = append(rules, rule{name: r.name})
+			continue
+		}
+
+		var validator, ctxValidator, ok = validate.lookup(r.name)
+		if !ok {
+			return nil, fmt.Errorf("%s validator does not exits", r.name)
+		}
+
+		//提前编译正则, 语法错误在第一次使用时就能发现
+		if r.name == "regexp" && len(r.param.Value) > 0 {
+			if _, err = compileRegexp(r.param.Value); err != nil {
+				return nil, err
+			}
+		}
+
+		rules = append(rules, rule{name: r.name, fn: validator, ctxFn: ctxValidator, param: r.param})
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,434 @@
+package validators
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// stringRule 只支持字符串字段的规则, 指针字段会解引用, nil 指针由 required 判断
+func stringRule(name string, check func(string) bool) ValidatorFunc {
+	return func(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+		var field = indirect(valueOf.Field(index))
+		if !field.IsValid() {
+			return nil
+		}
+
+		if field.Kind() != reflect.String {
+			return fmt.Errorf("%s only support string type", name)
+		}
+
+		if !check(field.String()) {
+			return param.Fail()
+		}
+
+		return nil
+	}
+}
+
+// indirect 解引用指针, nil 指针返回无效的 reflect.Value
+func indirect(field reflect.Value) reflect.Value {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return reflect.Value{}
+		}
+
+		field = field.Elem()
+	}
+
+	return field
+}
+
+// lengthOf 字符串按字符计算长度, 切片/数组/map按元素个数
+func lengthOf(field reflect.Value) (int, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(field.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return field.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+// Length 长度必须等于指定值, 支持字符串/切片/数组/map
+func Length(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var except, err = strconv.Atoi(param.Value)
+	if err != nil {
+		return err
+	}
+
+	var field = indirect(valueOf.Field(index))
+	if !field.IsValid() {
+		return nil
+	}
+
+	var length, ok = lengthOf(field)
+	if !ok {
+		return errors.New("len only support string, slice, array and map type")
+	}
+
+	if length != except {
+		return param.Fail()
+	}
+
+	return nil
+}
+
+func isIP(s string) bool {
+	return net.ParseIP(s) != nil
+}
+
+func isIPv4(s string) bool {
+	var ip = net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+}
+
+func isIPv6(s string) bool {
+	var ip = net.ParseIP(s)
+	return ip != nil && strings.Contains(s, ":")
+}
+
+func isCIDR(s string) bool {
+	var _, _, err = net.ParseCIDR(s)
+	return err == nil
+}
+
+func isHostname(s string) bool {
+	return len(s) <= 253 && hostnameRegexp.MatchString(s)
+}
+
+func isFQDN(s string) bool {
+	return len(s) <= 254 && fqdnRegexp.MatchString(s)
+}
+
+func isMAC(s string) bool {
+	var _, err = net.ParseMAC(s)
+	return err == nil
+}
+
+func isBase64(s string) bool {
+	var _, err = base64.StdEncoding.DecodeString(s)
+	return len(s) > 0 && err == nil
+}
+
+func isBase64URL(s string) bool {
+	var _, err = base64.URLEncoding.DecodeString(s)
+	return len(s) > 0 && err == nil
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+
+	return len(s) > 0
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return len(s) > 0
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// luhn 校验码算法, 信用卡和银行卡号使用
+func luhn(digits string) bool {
+	var sum int
+	var double bool
+	for i := len(digits) - 1; i >= 0; i-- {
+		var d = int(digits[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// stripSeparators 去掉卡号/书号中的空格和横线
+func stripSeparators(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(s)
+}
+
+func isCreditCard(s string) bool {
+	s = stripSeparators(s)
+	if len(s) < 12 || len(s) > 19 {
+		return false
+	}
+
+	return luhn(s)
+}
+
+func isBankCard(s string) bool {
+	s = stripSeparators(s)
+
+	return bankCardRegexp.MatchString(s) && luhn(s)
+}
+
+func isISBN10(s string) bool {
+	s = stripSeparators(s)
+	if len(s) != 10 {
+		return false
+	}
+
+	var sum int
+	for i := 0; i < 10; i++ {
+		var d int
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			d = int(c - '0')
+		case (c == 'X' || c == 'x') && i == 9:
+			d = 10
+		default:
+			return false
+		}
+
+		sum += (10 - i) * d
+	}
+
+	return sum%11 == 0
+}
+
+func isISBN13(s string) bool {
+	s = stripSeparators(s)
+	if len(s) != 13 {
+		return false
+	}
+
+	var sum int
+	for i := 0; i < 13; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+
+		var d = int(s[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+
+		sum += d
+	}
+
+	return sum%10 == 0
+}
+
+func isISBN(s string) bool {
+	return isISBN10(s) || isISBN13(s)
+}
+
+// isIDCard 18位居民身份证号, 校验出生日期和最后一位校验码
+func isIDCard(s string) bool {
+	if !idCardRegexp.MatchString(s) {
+		return false
+	}
+
+	if _, err := time.Parse("20060102", s[6:14]); err != nil {
+		return false
+	}
+
+	var weights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	var sum int
+	for i, w := range weights {
+		sum += int(s[i]-'0') * w
+	}
+
+	return "10X98765432"[sum%11] == strings.ToUpper(s[17:])[0]
+}
+
+// numberField 数值或者数值字符串
+func numberField(field reflect.Value) (float64, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), true
+	case reflect.String:
+		var value, err = strconv.ParseFloat(field.String(), 64)
+		return value, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// numberRange 数值必须在 [min, max] 范围内
+func numberRange(name string, min, max float64, integer bool) ValidatorFunc {
+	return func(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+		var field = indirect(valueOf.Field(index))
+		if !field.IsValid() {
+			return nil
+		}
+
+		var value, ok = numberField(field)
+		if !ok && field.Kind() != reflect.String {
+			return fmt.Errorf("%s only support number and string type", name)
+		}
+
+		//NaN 和任何数比较都是 false, 需要单独排除
+		if !ok || math.IsNaN(value) || math.IsInf(value, 0) || value < min || value > max || (integer && value != float64(int64(value))) {
+			return param.Fail()
+		}
+
+		return nil
+	}
+}
+
+// JSON 合法的json字符串, 支持 string 和 []byte(json.RawMessage)
+func JSON(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var field = indirect(valueOf.Field(index))
+	if !field.IsValid() {
+		return nil
+	}
+
+	var data []byte
+	switch {
+	case field.Kind() == reflect.String:
+		data = []byte(field.String())
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+		data = field.Bytes()
+	default:
+		return errors.New("json only support string and []byte type")
+	}
+
+	if !json.Valid(data) {
+		return param.Fail()
+	}
+
+	return nil
+}
+
+// OneOf 枚举值, 多个值使用空格分隔, 例: oneof(v=red green blue)
+func OneOf(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	if len(param.Value) == 0 {
+		return valueParamRequiredError
+	}
+
+	var field = indirect(valueOf.Field(index))
+	if !field.IsValid() {
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return errors.New("oneof only support string and integer type")
+	}
+
+	var text = fmt.Sprint(field.Interface())
+	for _, option := range strings.Fields(param.Value) {
+		if option == text {
+			return nil
+		}
+	}
+
+	return param.Fail()
+}
+
+// Unique 切片/数组的元素不能重复, map 的值不能重复
+func Unique(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var field = indirect(valueOf.Field(index))
+	if !field.IsValid() {
+		return nil
+	}
+
+	var values []reflect.Value
+	switch field.Kind() {
+	case reflect.Slice, reflect.Array:
+		values = make([]reflect.Value, field.Len())
+		for i := range values {
+			values[i] = field.Index(i)
+		}
+	case reflect.Map:
+		var iter = field.MapRange()
+		for iter.Next() {
+			values = append(values, iter.Value())
+		}
+	default:
+		return errors.New("unique only support slice, array and map type")
+	}
+
+	var seen = make(map[any]struct{}, len(values))
+	for _, value := range values {
+		var key any
+		if hashable(value.Type()) {
+			key = value.Interface()
+		} else {
+			key = fmt.Sprintf("%#v", value.Interface())
+		}
+
+		if _, ok := seen[key]; ok {
+			return param.Fail()
+		}
+
+		seen[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// hashable 类型的值可以作为 map 的键, Comparable 对含有 interface 字段的结构体也返回 true,
+// 但 interface 中是切片时会 panic
+func hashable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Array:
+		return hashable(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !hashable(t.Field(i).Type) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return t.Comparable()
+	}
+}
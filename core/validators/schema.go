@@ -0,0 +1,377 @@
+package validators
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// schemaDialect JSON Schema 版本
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaFunc 把规则转换成 JSON Schema 关键字, schema 中已经设置了字段的 type
+type SchemaFunc func(schema map[string]any, param Param)
+
+// Schema 使用全局默认的管理器把结构体的验证规则转换成 JSON Schema
+func Schema(v any) (map[string]any, error) {
+	return validatorLibrary.Schema(v)
+}
+
+// RegisterSchema 注册规则对应的 JSON Schema 转换函数到全局默认的管理器
+func RegisterSchema(key string, fn SchemaFunc) {
+	validatorLibrary.RegisterSchema(key, fn)
+}
+
+// RegisterSchema 注册或覆盖规则对应的 JSON Schema 转换函数
+func (validate *ValidatorLibrary) RegisterSchema(key string, fn SchemaFunc) {
+	validate.lock.Lock()
+	defer validate.lock.Unlock()
+
+	validate.schemas[key] = fn
+}
+
+// schemaFunc 获取规则的转换函数, 没有时该规则不出现在 schema 中
+func (validate *ValidatorLibrary) schemaFunc(key string) SchemaFunc {
+	validate.lock.RLock()
+	defer validate.lock.RUnlock()
+
+	return validate.schemas[key]
+}
+
+// Schema 把结构体的字段和验证规则转换成 JSON Schema draft 2020-12,
+// 嵌套的结构体放在 $defs 中, 递归的结构体也能正常输出
+func (validate *ValidatorLibrary) Schema(v any) (map[string]any, error) {
+	var t = reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema only support struct type, got %v", t)
+	}
+
+	var b = &schemaBuilder{library: validate, defs: make(map[string]any, 0), names: make(map[reflect.Type]string, 0)}
+	b.names[t] = "" //递归引用根结构体时使用 #
+	var schema, err = b.objectSchema(t)
+	if err != nil {
+		return nil, err
+	}
+
+	schema["$schema"] = schemaDialect
+	if len(t.Name()) > 0 {
+		schema["title"] = t.Name()
+	}
+
+	if len(b.defs) > 0 {
+		schema["$defs"] = b.defs
+	}
+
+	return schema, nil
+}
+
+// schemaBuilder 一次转换过程中的状态
+type schemaBuilder struct {
+	library *ValidatorLibrary
+	defs    map[string]any
+	names   map[reflect.Type]string //结构体在 $defs 中的名称
+}
+
+// typeSchema 根据 Go 类型生成 schema
+func (b *schemaBuilder) typeSchema(t reflect.Type) (map[string]any, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+	case t == fileHeaderType.Elem():
+		return map[string]any{"type": "string", "contentMediaType": "application/octet-stream"}, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		var items, err = b.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		var values, err = b.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return b.refSchema(t)
+	default:
+		return map[string]any{}, nil
+	}
+}
+
+// refSchema 具名结构体放到 $defs 中, 使用 $ref 引用
+func (b *schemaBuilder) refSchema(t reflect.Type) (map[string]any, error) {
+	if len(t.Name()) == 0 {
+		return b.objectSchema(t)
+	}
+
+	var name, ok = b.names[t]
+	if ok && len(name) == 0 {
+		return map[string]any{"$ref": "#"}, nil
+	}
+
+	if !ok {
+		name = t.Name()
+		for i := 2; b.defs[name] != nil; i++ { //不同包的同名结构体
+			name = t.Name() + strconv.Itoa(i)
+		}
+
+		b.names[t] = name
+		b.defs[name] = map[string]any{} //先占位, 递归的结构体直接引用
+
+		var schema, err = b.objectSchema(t)
+		if err != nil {
+			return nil, err
+		}
+
+		b.defs[name] = schema
+	}
+
+	return map[string]any{"$ref": "#/$defs/" + name}, nil
+}
+
+// objectSchema 结构体的所有导出字段, 字段名使用 json tag
+func (b *schemaBuilder) objectSchema(t reflect.Type) (map[string]any, error) {
+	var plan, err = b.library.plan(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules = make(map[int]fieldRules, len(plan.fields))
+	for _, field := range plan.fields {
+		rules[field.index] = field
+	}
+
+	var properties = make(map[string]any, 0)
+	var required = make([]string, 0)
+	if err = b.fields(t, rules, properties, &required); err != nil {
+		return nil, err
+	}
+
+	var schema = map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema, nil
+}
+
+// fields 添加结构体字段到 properties, 匿名结构体的字段和 json 一样平铺
+func (b *schemaBuilder) fields(t reflect.Type, rules map[int]fieldRules, properties map[string]any, required *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		var field = t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		var name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+		var fieldType = field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && len(name) == 0 && fieldType.Kind() == reflect.Struct {
+			var plan, err = b.library.plan(fieldType)
+			if err != nil {
+				return err
+			}
+
+			var embedded = make(map[int]fieldRules, len(plan.fields))
+			for _, fr := range plan.fields {
+				embedded[fr.index] = fr
+			}
+
+			if err = b.fields(fieldType, embedded, properties, required); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		var schema, err = b.typeSchema(field.Type)
+		if err != nil {
+			return err
+		}
+
+		if label, ok := field.Tag.Lookup(labelTag); ok {
+			schema["title"] = label
+		}
+
+		var fr = rules[i]
+		for _, r := range fr.rules {
+			if r.name == "required" {
+				*required = append(*required, jsonName(field))
+			}
+		}
+
+		b.applyRules(schema, fr.rules)
+		if items, ok := schema["items"].(map[string]any); ok && fr.dive {
+			b.applyRules(items, fr.elemRules)
+		}
+
+		if values, ok := schema["additionalProperties"].(map[string]any); ok && fr.dive {
+			b.applyRules(values, fr.elemRules)
+		}
+
+		properties[jsonName(field)] = schema
+	}
+
+	return nil
+}
+
+func (b *schemaBuilder) applyRules(schema map[string]any, rules []rule) {
+	for _, r := range rules {
+		if fn := b.library.schemaFunc(r.name); fn != nil {
+			fn(schema, r.param)
+		}
+	}
+}
+
+// lengthKeyword 字符串/数组/对象对应不同的长度关键字
+func lengthKeyword(schema map[string]any, prefix string) string {
+	switch schema["type"] {
+	case "array":
+		return prefix + "Items"
+	case "object":
+		return prefix + "Properties"
+	default:
+		return prefix + "Length"
+	}
+}
+
+// schemaNumber 规则参数转换成数值, 不是数值时保持字符串
+func schemaNumber(value string) any {
+	value = strings.TrimSpace(value)
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+
+	return value
+}
+
+// schemaKeyword 把规则参数设置为某个关键字
+func schemaKeyword(keyword string) SchemaFunc {
+	return func(schema map[string]any, param Param) {
+		schema[keyword] = schemaNumber(param.Value)
+	}
+}
+
+// schemaConst eq/equal, 字符串字段保留原始字符串
+func schemaConst(schema map[string]any, param Param) {
+	if schema["type"] == "string" {
+		schema["const"] = param.Value
+		return
+	}
+
+	schema["const"] = schemaNumber(param.Value)
+}
+
+// schemaSet 设置固定的关键字
+func schemaSet(keyword string, value any) SchemaFunc {
+	return func(schema map[string]any, param Param) {
+		schema[keyword] = value
+	}
+}
+
+// schemaRange 固定的数值范围
+func schemaRange(min, max float64) SchemaFunc {
+	return func(schema map[string]any, param Param) {
+		schema["minimum"] = min
+		schema["maximum"] = max
+	}
+}
+
+// builtinSchemas 内置规则对应的 JSON Schema 关键字
+func builtinSchemas() map[string]SchemaFunc {
+	return map[string]SchemaFunc{
+		"max_length": func(schema map[string]any, param Param) {
+			//max_length 不包含边界
+			if n, err := strconv.Atoi(param.Value); err == nil {
+				schema[lengthKeyword(schema, "max")] = n - 1
+			}
+		},
+		"min_length": func(schema map[string]any, param Param) {
+			if n, err := strconv.Atoi(param.Value); err == nil {
+				schema[lengthKeyword(schema, "min")] = n + 1
+			}
+		},
+		"len": func(schema map[string]any, param Param) {
+			if n, err := strconv.Atoi(param.Value); err == nil {
+				schema[lengthKeyword(schema, "min")] = n
+				schema[lengthKeyword(schema, "max")] = n
+			}
+		},
+		"between": func(schema map[string]any, param Param) {
+			var min, max, ok = strings.Cut(param.Value, ",")
+			if ok && schema["type"] != "string" {
+				schema["minimum"] = schemaNumber(min)
+				schema["maximum"] = schemaNumber(max)
+			}
+		},
+		"gt":    schemaKeyword("exclusiveMinimum"),
+		"ge":    schemaKeyword("minimum"),
+		"lt":    schemaKeyword("exclusiveMaximum"),
+		"le":    schemaKeyword("maximum"),
+		"eq":    schemaConst,
+		"equal": schemaConst,
+		"oneof": func(schema map[string]any, param Param) {
+			var options = strings.Fields(param.Value)
+			var enum = make([]any, len(options))
+			for i, option := range options {
+				if schema["type"] == "string" {
+					enum[i] = option
+				} else {
+					enum[i] = schemaNumber(option)
+				}
+			}
+
+			schema["enum"] = enum
+		},
+		"regexp": func(schema map[string]any, param Param) {
+			schema["pattern"] = param.Value
+		},
+		"email":     schemaSet("format", "email"),
+		"uuid":      schemaSet("format", "uuid"),
+		"url":       schemaSet("format", "uri"),
+		"ipv4":      schemaSet("format", "ipv4"),
+		"ipv6":      schemaSet("format", "ipv6"),
+		"hostname":  schemaSet("format", "hostname"),
+		"phone":     schemaSet("pattern", phoneRegx),
+		"hex_color": schemaSet("pattern", hexColorRegx),
+		"semver":    schemaSet("pattern", semverRegx),
+		"id_card":   schemaSet("pattern", idCardRegx),
+		"alpha":     schemaSet("pattern", "^[a-zA-Z]+$"),
+		"unique":    schemaSet("uniqueItems", true),
+		"latitude":  schemaRange(-90, 90),
+		"longitude": schemaRange(-180, 180),
+		"port":      schemaRange(1, 65535),
+	}
+}
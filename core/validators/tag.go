@@ -0,0 +1,229 @@
+package validators
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TagError validate tag 语法错误或者规则不存在, 第一次验证该结构体时返回并缓存
+type TagError struct {
+	Struct string //结构体名称
+	Field  string //字段名称
+	Tag    string //原始 tag
+	Err    error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("validators: %s.%s: invalid tag %q: %v", e.Struct, e.Field, e.Tag, e.Err)
+}
+
+// Unwrap 获取原始错误
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// parsedRule tag 中的一条规则, 还没有关联验证器
+type parsedRule struct {
+	name  string
+	param Param
+}
+
+// tagParser validate tag 解析器, 语法:
+//
+//	rule;rule rule
+//	name(key=value,key="quoted, value",key='single')
+//
+// 规则之间使用分号或空格分隔; 参数值可以使用引号, 引号中 \" \' \\ 需要转义;
+// 没有引号时括号需要成对或者使用 \( \) 转义, 逗号分号可以用 \, \; 转义;
+// 不是 key=value 形式的片段属于前一个参数, 兼容 v=1,120 的写法
+type tagParser struct {
+	tag string
+	pos int
+}
+
+// parseTag 解析 validate tag
+func parseTag(tag string) ([]parsedRule, error) {
+	var p = &tagParser{tag: tag}
+	var rules []parsedRule
+	for {
+		p.skip(" \t;")
+		if p.eof() {
+			return rules, nil
+		}
+
+		var name = p.ident()
+		if len(name) == 0 {
+			return nil, p.errorf("expected rule name, got %q", p.tag[p.pos])
+		}
+
+		var r = parsedRule{name: name}
+		if !p.eof() && p.tag[p.pos] == '(' {
+			p.pos++
+			var err error
+			if r.param, err = p.params(); err != nil {
+				return nil, err
+			}
+		}
+
+		if !p.eof() && !strings.ContainsRune(" \t;", rune(p.tag[p.pos])) {
+			return nil, p.errorf("unexpected %q after rule %s", p.tag[p.pos], name)
+		}
+
+		rules = append(rules, r)
+	}
+}
+
+// params 解析括号中的参数, 直到匹配的右括号
+func (p *tagParser) params() (Param, error) {
+	var param Param
+	var keys, values []string
+	var quoted bool
+	for {
+		var start = p.pos
+		p.skip(" \t")
+		if p.eof() {
+			return param, p.errorf("unclosed (")
+		}
+
+		if len(keys) == 0 && p.tag[p.pos] == ')' {
+			p.pos++
+			break
+		}
+
+		var key = p.ident()
+		p.skip(" \t")
+		if len(key) > 0 && !p.eof() && p.tag[p.pos] == '=' {
+			p.pos++
+			var value, isQuoted, err = p.value()
+			if err != nil {
+				return param, err
+			}
+
+			keys, values, quoted = append(keys, key), append(values, value), isQuoted
+		} else {
+			//不是 key=value, 属于前一个参数的值
+			p.pos = start
+			if len(keys) == 0 {
+				return param, p.errorf("expected key=value")
+			}
+
+			if quoted {
+				return param, p.errorf("unexpected value after quoted %s", keys[len(keys)-1])
+			}
+
+			values[len(values)-1] += "," + p.bare()
+		}
+
+		p.skip(" \t")
+		if p.eof() {
+			return param, p.errorf("unclosed (")
+		}
+
+		var c = p.tag[p.pos]
+		p.pos++
+		if c == ')' {
+			break
+		}
+
+		if c != ',' {
+			return param, p.errorf("unexpected %q", c)
+		}
+	}
+
+	for i, key := range keys {
+		switch key {
+		case "m", "message":
+			param.Message = values[i]
+		case "v", "value":
+			param.Value = values[i]
+		default:
+			if param.Params == nil {
+				param.Params = make(map[string]string, 0)
+			}
+
+			param.Params[key] = values[i]
+		}
+	}
+
+	return param, nil
+}
+
+// value 解析参数值, 支持引号
+func (p *tagParser) value() (string, bool, error) {
+	if p.eof() || (p.tag[p.pos] != '"' && p.tag[p.pos] != '\'') {
+		return p.bare(), false, nil
+	}
+
+	var quote = p.tag[p.pos]
+	var b strings.Builder
+	for p.pos++; !p.eof(); p.pos++ {
+		var c = p.tag[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), true, nil
+		case c == '\\' && p.pos+1 < len(p.tag) && strings.IndexByte(`"'\`, p.tag[p.pos+1]) >= 0:
+			p.pos++
+			b.WriteByte(p.tag[p.pos])
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", false, p.errorf("unclosed quote %c", quote)
+}
+
+// bare 解析没有引号的值, 到同一层级的逗号或者右括号为止
+func (p *tagParser) bare() string {
+	var b strings.Builder
+	var depth int
+	for ; !p.eof(); p.pos++ {
+		var c = p.tag[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.tag) && strings.IndexByte(`,;()"'\`, p.tag[p.pos+1]) >= 0:
+			//只转义特殊字符, 正则中的 \d \w 保持不变
+			p.pos++
+			b.WriteByte(p.tag[p.pos])
+			continue
+		case c == '(':
+			depth++
+		case c == ')' && depth == 0, c == ',' && depth == 0:
+			return b.String()
+		case c == ')':
+			depth--
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// ident 规则名或参数名
+func (p *tagParser) ident() string {
+	var start = p.pos
+	for !p.eof() {
+		var c = p.tag[p.pos]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			break
+		}
+
+		p.pos++
+	}
+
+	return p.tag[start:p.pos]
+}
+
+func (p *tagParser) skip(chars string) {
+	for !p.eof() && strings.IndexByte(chars, p.tag[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *tagParser) eof() bool {
+	return p.pos >= len(p.tag)
+}
+
+func (p *tagParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
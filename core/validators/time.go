@@ -0,0 +1,275 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTimeLayouts 字符串字段没有设置 layout 时依次尝试
+var defaultTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// timeNow 当前时间, 测试时可以替换
+var timeNow = time.Now
+
+// timeLocation 参数 tz 指定的时区, 默认本地时区
+func timeLocation(param Param) (*time.Location, error) {
+	var tz = param.Params["tz"]
+	if len(tz) == 0 {
+		return time.Local, nil
+	}
+
+	return time.LoadLocation(tz)
+}
+
+// parseTime 按 layout 解析时间字符串, 没有时区信息的时间使用 loc
+func parseTime(text string, param Param, loc *time.Location) (time.Time, error) {
+	if layout, ok := param.Params["layout"]; ok {
+		return time.ParseInLocation(layout, text, loc)
+	}
+
+	var err error
+	for _, layout := range defaultTimeLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, text, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+// timeField 获取时间字段, 支持 time.Time 和时间字符串, 空值返回 false
+func timeField(field reflect.Value, param Param, loc *time.Location) (time.Time, bool, error) {
+	field = indirect(field)
+	switch {
+	case !field.IsValid():
+		return time.Time{}, false, nil
+	case field.Type() == timeType:
+		var t = field.Interface().(time.Time)
+		return t, !t.IsZero(), nil
+	case field.Kind() == reflect.String:
+		if len(field.String()) == 0 {
+			return time.Time{}, false, nil
+		}
+
+		var t, err = parseTime(field.String(), param, loc)
+		if err != nil {
+			return time.Time{}, false, param.Fail()
+		}
+
+		return t, true, nil
+	default:
+		return time.Time{}, false, errors.New("time rules only support time.Time and string type")
+	}
+}
+
+// parseDuration 在 time.ParseDuration 的基础上支持天, 例: 7d, 1d12h
+func parseDuration(text string) (time.Duration, error) {
+	var days time.Duration
+	if idx := strings.Index(text, "d"); idx > 0 {
+		var n, err = strconv.Atoi(text[:idx])
+		if err != nil {
+			return 0, err
+		}
+
+		days = time.Duration(n) * 24 * time.Hour
+		if text = text[idx+1:]; len(text) == 0 {
+			return days, nil
+		}
+	}
+
+	var d, err = time.ParseDuration(text)
+	return days + d, err
+}
+
+// resolveTime 解析规则中的时间值:
+// now, today, now+24h, today-7d 相对时间; 同结构体的其他字段名; 或者时间字符串
+func resolveTime(text string, valueOf reflect.Value, param Param, loc *time.Location) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	var now = timeNow().In(loc)
+	for _, base := range []string{"now", "today"} {
+		if !strings.HasPrefix(text, base) {
+			continue
+		}
+
+		var t = now
+		if base == "today" {
+			t = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+		}
+
+		var offset = strings.TrimSpace(strings.TrimPrefix(text, base))
+		if len(offset) == 0 {
+			return t, nil
+		}
+
+		var sign = offset[0]
+		if sign != '+' && sign != '-' {
+			return time.Time{}, fmt.Errorf("%s syntax error", text)
+		}
+
+		var d, err = parseDuration(strings.TrimSpace(offset[1:]))
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if sign == '-' {
+			d = -d
+		}
+
+		return t.Add(d), nil
+	}
+
+	if other := valueOf.FieldByName(text); other.IsValid() {
+		var t, ok, err = timeField(other, param, loc)
+		if err != nil || !ok {
+			return time.Time{}, fmt.Errorf("field %s is not a valid time", text)
+		}
+
+		return t, nil
+	}
+
+	return parseTime(text, param, loc)
+}
+
+// compareTime 字段时间和规则中的时间比较, 空值不验证(是否必填由 required 决定)
+func compareTime(valueOf reflect.Value, index int, param Param, check func(t time.Time, bounds []time.Time) bool, count int) error {
+	if len(param.Value) == 0 {
+		return valueParamRequiredError
+	}
+
+	var loc, err = timeLocation(param)
+	if err != nil {
+		return err
+	}
+
+	var t, ok, err01 = timeField(valueOf.Field(index), param, loc)
+	if err01 != nil || !ok {
+		return err01
+	}
+
+	var texts = strings.Split(param.Value, ",")
+	if len(texts) != count {
+		return fmt.Errorf("%s syntax error", param.Value)
+	}
+
+	var bounds = make([]time.Time, count)
+	for i, text := range texts {
+		if bounds[i], err = resolveTime(text, valueOf, param, loc); err != nil {
+			return err
+		}
+	}
+
+	if !check(t, bounds) {
+		return param.Fail()
+	}
+
+	return nil
+}
+
+// Before 时间必须早于指定时间, 例: before(v=now+24h), before(v=EndAt)
+func Before(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	return compareTime(valueOf, index, param, func(t time.Time, bounds []time.Time) bool {
+		return t.Before(bounds[0])
+	}, 1)
+}
+
+// After 时间必须晚于指定时间, 例: after(v=today), after(v=StartAt)
+func After(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	return compareTime(valueOf, index, param, func(t time.Time, bounds []time.Time) bool {
+		return t.After(bounds[0])
+	}, 1)
+}
+
+// TimeBetween 时间必须在范围内(包含边界), 例: between(v=today,today+30d)
+func TimeBetween(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	return compareTime(valueOf, index, param, func(t time.Time, bounds []time.Time) bool {
+		return !t.Before(bounds[0]) && !t.After(bounds[1])
+	}, 2)
+}
+
+// isTimeField 字段是否是时间类型, between 根据字段类型选择数值或时间比较
+func isTimeField(field reflect.Value, param Param) bool {
+	if field.Kind() == reflect.Ptr {
+		return field.Type().Elem() == timeType
+	}
+
+	if field.Type() == timeType {
+		return true
+	}
+
+	//字符串字段设置了 layout, 范围是相对时间, 或者范围是默认格式的日期, 例: between(v=2020-01-01,2030-12-31)
+	if field.Kind() == reflect.String {
+		var _, ok = param.Params["layout"]
+		return ok || strings.HasPrefix(param.Value, "now") || strings.HasPrefix(param.Value, "today") || isTimeBounds(param.Value)
+	}
+
+	return false
+}
+
+// isTimeBounds 范围的两端都能按默认格式解析成时间
+func isTimeBounds(value string) bool {
+	var bounds = strings.Split(value, ",")
+	if len(bounds) != 2 {
+		return false
+	}
+
+	for _, bound := range bounds {
+		if _, err := parseTime(strings.TrimSpace(bound), Param{}, time.UTC); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// age 按生日计算周岁
+func age(birthday, now time.Time) int {
+	var years = now.Year() - birthday.Year()
+	if now.Month() < birthday.Month() || (now.Month() == birthday.Month() && now.Day() < birthday.Day()) {
+		years--
+	}
+
+	return years
+}
+
+// checkAge 生日字段的周岁和规则中的年龄比较
+func checkAge(valueOf reflect.Value, index int, param Param, check func(age, except int) bool) error {
+	var except, err = strconv.Atoi(param.Value)
+	if err != nil {
+		return err
+	}
+
+	var loc *time.Location
+	if loc, err = timeLocation(param); err != nil {
+		return err
+	}
+
+	var birthday, ok, err01 = timeField(valueOf.Field(index), param, loc)
+	if err01 != nil || !ok {
+		return err01
+	}
+
+	if !check(age(birthday.In(loc), timeNow().In(loc)), except) {
+		return param.Fail()
+	}
+
+	return nil
+}
+
+// MinAge 周岁不能小于指定值, 例: min_age(v=18)
+func MinAge(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	return checkAge(valueOf, index, param, func(age, except int) bool {
+		return age >= except
+	})
+}
+
+// MaxAge 周岁不能大于指定值
+func MaxAge(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	return checkAge(valueOf, index, param, func(age, except int) bool {
+		return age <= except
+	})
+}
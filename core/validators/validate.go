@@ -1,18 +1,20 @@
 package validators
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"image"
 	"reflect"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
-	"unicode/utf8"
 )
 
 const (
 	validatorTag = "validate"
+	labelTag     = "label" //错误消息中的字段名
 )
 
 // Validator 验证器接口
@@ -20,78 +22,173 @@ type Validator interface {
 	Validate(any) error
 }
 
+// ContextValidator 可以接收请求上下文的验证器, Context.Data 优先使用
+type ContextValidator interface {
+	ValidateContext(ctx context.Context, value any) error
+}
+
+// Validatable 结构体级别的验证, 在所有字段规则之后调用
+type Validatable interface {
+	Validate() error
+}
+
+// ContextValidatable 需要上下文的结构体级别验证, 例: 查询数据库检测唯一性
+type ContextValidatable interface {
+	ValidateContext(ctx context.Context) error
+}
+
 // ValidatorFunc 验证器回调函数
 type ValidatorFunc func(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error
 
-// ValidatorLibrary 定义验证器管理器
-type ValidatorLibrary map[string]ValidatorFunc
+// ContextValidatorFunc 可以接收上下文的验证器回调函数
+type ContextValidatorFunc func(ctx context.Context, typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error
 
-// validatorLibrary验证器管理器
-var validatorLibrary ValidatorLibrary = make(map[string]ValidatorFunc, 0)
+var (
+	validatableType        = reflect.TypeOf((*Validatable)(nil)).Elem()
+	contextValidatableType = reflect.TypeOf((*ContextValidatable)(nil)).Elem()
+)
 
-func (validate ValidatorLibrary) Validate(value any) error {
-	var t = reflect.TypeOf(value)
-	for t.Kind() == reflect.Ptr { //解引用(去指针化)
-		t = t.Elem()
-	}
+// walker 一次验证过程中的状态
+type walker struct {
+	library  *ValidatorLibrary
+	ctx      context.Context
+	locales  []string //验证消息的语言
+	failFast bool
+	errs     ValidationErrors
+}
 
-	var v = reflect.ValueOf(value)
-	for reflect.Ptr == v.Kind() { //解引用(去指针化)
-		v = v.Elem()
+// validateStruct 验证结构体的所有字段, 并递归验证嵌套的结构体/切片/map,
+// 错误路径形如 items[2].sku
+func (w *walker) validateStruct(v reflect.Value, prefix string) error {
+	return w.validateFields(v, prefix, true)
+}
+
+// validateFields hooks 为 false 时不调用结构体的 Validate 方法
+func (w *walker) validateFields(v reflect.Value, prefix string, hooks bool) error {
+	var t = v.Type()
+	var plan, err = w.library.plan(t)
+	if err != nil {
+		return err
 	}
 
-	for i := 0; i < t.NumField(); i++ {
-		var tags, ok = t.Field(i).Tag.Lookup(validatorTag)
-		if !ok {
+	for _, field := range plan.fields {
+		var path = prefix
+		if !field.embedded {
+			path = joinPath(prefix, field.path)
+		}
+
+		if field.omitempty && !isValid(v.Field(field.index)) {
 			continue
 		}
 
-		// 根据分号分隔
-		// required(m=姓名不能为空);max_length(m=姓名长度不能大于10,value=10)
-		// required(m=姓名不能为空)  max_length(m=姓名长度不能大于10,value=10)
-		for _, validateName := range strings.Split(tags, ";") {
-			var matchList = validateParamRegexp.FindAllStringSubmatch(validateName, -1)
-			if len(matchList) == 0 {
-				continue
+		var ok bool
+		if ok, err = w.applyRules(t, v, field.index, field, path, field.rules); err != nil || !ok {
+			if err != nil {
+				return err
 			}
 
-			var result = matchList[0]
-			var key = result[1]
-			var validator, ok = validate[key]
-			if !ok {
-				return fmt.Errorf("%s validator does not exits", key)
+			continue //字段本身验证失败, 不再验证元素
+		}
+
+		var fieldValue = v.Field(field.index)
+		if field.dive {
+			if err = w.validateElems(fieldValue, field, path); err != nil {
+				return err
 			}
 
-			var text = result[2]
-			var list = strings.Split(text, ",")
-			if len(list) > 2 {
-				list[1] = strings.TrimPrefix(text, list[0]+",")
-				list = list[:2]
+			continue
+		}
+
+		if field.nested {
+			if err = w.validateNested(fieldValue, path, !field.promoted); err != nil {
+				return err
 			}
+		}
+	}
+
+	if !hooks {
+		return nil
+	}
 
-			var param Param
-			for idx, val := range list {
-				if strings.HasPrefix(val, " ") {
-					list[idx] = strings.TrimPrefix(val, " ")
-				}
-
-				var item = strings.Split(list[idx], "=")
-				if len(item) != 2 {
-					return fmt.Errorf("%s syntax error", list[idx])
-				}
-
-				switch item[0] {
-				case "m", "message":
-					param.Message = item[1]
-				case "v", "value":
-					param.Value = item[1]
-				default:
-					return fmt.Errorf("unexpect param got %s", item[0])
-				}
+	return w.validateHooks(v, prefix)
+}
+
+// applyRules 依次执行规则, 同一个字段只记录第一条失败的规则, 返回字段是否验证通过
+func (w *walker) applyRules(t reflect.Type, v reflect.Value, index int, field fieldRules, path string, rules []rule) (bool, error) {
+	for _, r := range rules {
+		var err error
+		if r.ctxFn != nil {
+			err = r.ctxFn(w.ctx, t, v, index, r.param)
+		} else {
+			err = r.fn(t, v, index, r.param)
+		}
+
+		if err == nil {
+			continue
+		}
+
+		var validationErr = NewValidationError(err, field.name, r.name)
+		validationErr.Path = path
+		var ruleErr *ruleError
+		if errors.As(err, &ruleErr) {
+			validationErr.Message = w.library.render(ruleErr.message, r.name, w.locales, messageData{
+				field: field.label,
+				value: v.Field(index),
+				param: r.param,
+			})
+		}
+
+		if w.failFast {
+			return false, validationErr
+		}
+
+		w.errs = append(w.errs, validationErr)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// validateElems dive 验证切片/数组/map的每个元素
+func (w *walker) validateElems(v reflect.Value, field fieldRules, path string) error {
+	var validateElem = func(elem reflect.Value, elemPath string) error {
+		if field.elemOmit && !isValid(elem) {
+			return nil
+		}
+
+		if field.holder != nil {
+			//包装成只有一个字段的结构体, ValidatorFunc 按字段验证
+			var holder = reflect.New(field.holder).Elem()
+			holder.Field(0).Set(elem)
+			var ok, err = w.applyRules(field.holder, holder, 0, field, elemPath, field.elemRules)
+			if err != nil || !ok {
+				return err
 			}
+		}
+
+		if field.nested {
+			return w.validateNested(elem, elemPath, true)
+		}
 
-			if err := validator(t, v, i, param); err != nil {
-				return NewValidationError(err, t.Field(i).Name, key)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateElem(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var keys = v.MapKeys()
+		//保证错误顺序稳定
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			if err := validateElem(v.MapIndex(key), fmt.Sprintf("%s[%v]", path, key.Interface())); err != nil {
+				return err
 			}
 		}
 	}
@@ -99,16 +196,115 @@ func (validate ValidatorLibrary) Validate(value any) error {
 	return nil
 }
 
-// RegisterValidator 注册验证器
-func RegisterValidator(key string, val ValidatorFunc) error {
-	if _, ok := validatorLibrary[key]; ok {
-		return fmt.Errorf("key %s has already exits", key)
+// validateNested 递归验证结构体, nil 指针跳过(是否必填由字段规则决定)
+func (w *walker) validateNested(v reflect.Value, path string, hooks bool) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return w.validateFields(v, path, hooks)
+}
+
+// validateHooks 调用结构体实现的 Validate/ValidateContext 方法, 指针接收者的方法也能调用
+func (w *walker) validateHooks(v reflect.Value, path string) error {
+	var t = v.Type()
+	var pt = reflect.PtrTo(t)
+	if !hasHooks(t) {
+		return nil
+	}
+
+	var receiver = v
+	if v.CanAddr() {
+		receiver = v.Addr()
+	} else if pt.Implements(validatableType) || pt.Implements(contextValidatableType) {
+		//不可寻址的值复制一份
+		receiver = reflect.New(t)
+		receiver.Elem().Set(v)
+	}
+
+	var err error
+	switch hook := receiver.Interface().(type) {
+	case ContextValidatable:
+		err = hook.ValidateContext(w.ctx)
+	case Validatable:
+		err = hook.Validate()
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	return w.addHookError(err, t.Name(), path)
+}
+
+// hasHooks 结构体(或结构体指针)实现了 Validatable 或 ContextValidatable
+func hasHooks(t reflect.Type) bool {
+	return len(hookMethod(t)) > 0
+}
+
+// hookMethod validateHooks 会调用的方法名, ValidateContext 优先
+func hookMethod(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var pt = reflect.PtrTo(t)
+	switch {
+	case t.Implements(contextValidatableType) || pt.Implements(contextValidatableType):
+		return "ValidateContext"
+	case t.Implements(validatableType) || pt.Implements(validatableType):
+		return "Validate"
+	default:
+		return ""
+	}
+}
+
+// addHookError 结构体方法返回的错误, 验证错误的路径加上结构体所在的路径
+func (w *walker) addHookError(err error, name, path string) error {
+	var errs ValidationErrors
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &errs):
+	case errors.As(err, &validationErr):
+		errs = ValidationErrors{validationErr}
+	default:
+		validationErr = NewValidationError(err, name, "validate")
+		validationErr.Path = path
+		errs = ValidationErrors{validationErr}
+	}
+
+	for _, e := range errs {
+		if e.Path != path {
+			e.Path = joinPath(path, e.Path)
+		}
+
+		if w.failFast {
+			return e
+		}
+
+		w.errs = append(w.errs, e)
 	}
 
-	validatorLibrary[key] = val
 	return nil
 }
 
+// joinPath 拼接嵌套字段的路径
+func joinPath(prefix, name string) string {
+	if len(prefix) == 0 {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
 // isValid 检测值是否位空
 func isValid(value reflect.Value) bool {
 	switch value.Kind() {
@@ -131,62 +327,43 @@ func isValid(value reflect.Value) bool {
 	}
 }
 
-// Required 必填字段
+// Required 必填字段, 零值/空字符串/空切片/nil 指针都视为未填写
 func Required(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
-
-	//获取当前字段
-	var field = valueOf.Field(index)
-	if field.Kind() == reflect.String {
-		var except, err = strconv.Atoi(param.Value)
-		if err != nil {
-			return err
-		}
-
-		if utf8.RuneCountInString(field.String()) >= except {
-			return errors.New(param.Message) //返回用户自定义错误信息
-		}
+	if !isValid(valueOf.Field(index)) {
+		return param.Fail()
 	}
 
 	return nil
 }
-func MaxLength(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
 
-	var field = valueOf.Field(index)
-	if field.Kind() == reflect.String {
+// MaxLength 字段最大长度, 支持字符串/切片/数组/map
+func MaxLength(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var length, ok = lengthOf(indirect(valueOf.Field(index)))
+	if ok {
 		var except, err = strconv.Atoi(param.Value)
 		if err != nil {
 			return err
 		}
 
-		if utf8.RuneCountInString(field.String()) >= except {
-			return errors.New(param.Message)
+		if length >= except {
+			return param.Fail()
 		}
 	}
 
 	return nil
 }
 
-// MinLength 字段最小长度
+// MinLength 字段最小长度, 支持字符串/切片/数组/map
 func MinLength(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
-
-	var field = valueOf.Field(index)
-	if field.Kind() == reflect.String {
+	var length, ok = lengthOf(indirect(valueOf.Field(index)))
+	if ok {
 		var except, err = strconv.Atoi(param.Value)
 		if err != nil {
 			return err
 		}
 
-		if utf8.RuneCountInString(field.String()) <= except {
-			return errors.New(param.Message)
+		if length <= except {
+			return param.Fail()
 		}
 	}
 
@@ -225,10 +402,6 @@ func isGt(field reflect.Value, v string) (bool, error) {
 }
 
 func GreaterThan(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
-
 	if len(param.Value) == 0 {
 		return valueParamRequiredError
 	}
@@ -239,7 +412,7 @@ func GreaterThan(typeOf reflect.Type, valueOf reflect.Value, index int, param Pa
 	}
 
 	if !ok {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 
 	return nil
@@ -277,10 +450,6 @@ func isLt(field reflect.Value, v string) (bool, error) {
 }
 
 func LowerThan(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
-
 	if len(param.Value) == 0 {
 		return valueParamRequiredError
 	}
@@ -291,7 +460,7 @@ func LowerThan(typeOf reflect.Type, valueOf reflect.Value, index int, param Para
 	}
 
 	if !ok {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 
 	return err
@@ -326,9 +495,6 @@ func isGe(field reflect.Value, v string) (bool, error) {
 }
 
 func GreaterEqual(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
 	if len(param.Value) == 0 {
 		return valueParamRequiredError
 	}
@@ -338,7 +504,7 @@ func GreaterEqual(typeOf reflect.Type, valueOf reflect.Value, index int, param P
 		return err
 	}
 	if !ok {
-		return errors.New(param.Value)
+		return param.Fail()
 	}
 
 	return err
@@ -369,9 +535,6 @@ func isLe(field reflect.Value, v string) (bool, error) {
 }
 
 func LowerEqual(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -380,7 +543,7 @@ func LowerEqual(typeOf reflect.Type, valueOf reflect.Value, index int, param Par
 		return err
 	}
 	if !ok {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
@@ -418,9 +581,6 @@ func isEqual(field reflect.Value, v string) (bool, error) {
 }
 
 func Equal(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -429,15 +589,12 @@ func Equal(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) e
 		return err
 	}
 	if !ok {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 
 func EqualField(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -446,22 +603,30 @@ func EqualField(typeOf reflect.Type, valueOf reflect.Value, index int, param Par
 		return fmt.Errorf("no field named %s", param.Value)
 	}
 	if !reflect.DeepEqual(valueOf.Field(index).Interface(), f.Interface()) {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 
+// Method 调用结构体的方法验证, 方法签名和 ValidatorFunc 相同, 指针接收者的方法也能调用
 func Method(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
 	if len(param.Value) == 0 {
 		return valueParamRequiredError
 	}
 
 	var method = valueOf.MethodByName(param.Value)
-	if method.IsValid() {
+	if !method.IsValid() && valueOf.CanAddr() {
+		method = valueOf.Addr().MethodByName(param.Value)
+	}
+
+	if !method.IsValid() {
 		return fmt.Errorf("no methods name %s", param.Value)
 	}
 
-	if fn, ok := method.Interface().(ValidatorFunc); ok {
+	switch fn := method.Interface().(type) {
+	case ValidatorFunc:
+		return fn(typeOf, valueOf, index, param)
+	case func(reflect.Type, reflect.Value, int, Param) error:
 		return fn(typeOf, valueOf, index, param)
 	}
 
@@ -469,66 +634,57 @@ func Method(typeOf reflect.Type, valueOf reflect.Value, index int, param Param)
 }
 
 func Regexp(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
 	if len(param.Value) == 0 {
 		return valueParamRequiredError
 	}
 
 	var field = valueOf.Field(index)
-	var reg = regexp.MustCompile(param.Value)
 	if field.Kind() != reflect.String {
 		return fmt.Errorf("Regexp only support string type")
 	}
 
+	var reg, err = compileRegexp(param.Value)
+	if err != nil {
+		return err
+	}
+
 	if !reg.MatchString(field.String()) {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 
 	return nil
 }
 
 func Email(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
-
 	var field = valueOf.Field(index)
 	if field.Kind() != reflect.String {
 		return errors.New("Email only support string type")
 	}
 
 	if !emailRegex.MatchString(field.String()) {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 
 func UUID(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	field := valueOf.Field(index)
 	if field.Kind() != reflect.String {
 		return errors.New("UUID only support string type")
 	}
 	if !uuidRegexp.MatchString(field.String()) {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 
 func Phone(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	field := valueOf.Field(index)
 	if field.Kind() != reflect.String {
 		return errors.New("Phone only support string type")
 	}
 	if !phoneRegexp.MatchString(field.String()) {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
@@ -573,12 +729,12 @@ func isBetween(field reflect.Value, min, max string) (bool, error) {
 
 // Between 在 T 范围内
 func Between(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
+	if isTimeField(valueOf.Field(index), param) {
+		return TimeBetween(typeOf, valueOf, index, param)
+	}
 	value := strings.Split(param.Value, ",")
 	if len(value) != 2 {
 		return fmt.Errorf("%s syntax error", param.Value)
@@ -590,16 +746,13 @@ func Between(typeOf reflect.Type, valueOf reflect.Value, index int, param Param)
 		return err
 	}
 	if !ok {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 
 // NotBetween 不在 T 范围内
 func NotBetween(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -614,14 +767,11 @@ func NotBetween(typeOf reflect.Type, valueOf reflect.Value, index int, param Par
 		return err
 	}
 	if ok {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 func NotEqual(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -630,15 +780,12 @@ func NotEqual(typeOf reflect.Type, valueOf reflect.Value, index int, param Param
 		return err
 	}
 	if ok {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 
 func NotEqualField(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -647,22 +794,19 @@ func NotEqualField(typeOf reflect.Type, valueOf reflect.Value, index int, param
 		return fmt.Errorf("no field named %s", param.Value)
 	}
 	if reflect.DeepEqual(valueOf.Field(index).Interface(), f.Interface()) {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 
 // Url 超链接判断
 func Url(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	field := valueOf.Field(index)
 	if field.Kind() != reflect.String {
 		return errors.New("Url only support string type")
 	}
 	if !urlRegexp.MatchString(field.String()) {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
@@ -721,9 +865,6 @@ func isContains(field reflect.Value, value string) (bool, error) {
 }
 
 func Contains(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -732,15 +873,12 @@ func Contains(typeOf reflect.Type, valueOf reflect.Value, index int, param Param
 		return err
 	}
 	if !ok {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 
 func NotContains(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -749,15 +887,12 @@ func NotContains(typeOf reflect.Type, valueOf reflect.Value, index int, param Pa
 		return err
 	}
 	if ok {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return nil
 }
 
 func DateFormat(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
 	if len(param.Value) == 0 {
 		return valueParamRequiredError
 	}
@@ -768,18 +903,15 @@ func DateFormat(typeOf reflect.Type, valueOf reflect.Value, index int, param Par
 	}
 
 	if _, err := time.Parse(param.Value, field.String()); err != nil {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 
 	return nil
 }
 
 func GreatThanField(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
 	if len(param.Value) == 0 {
-		return messageParamRequiredError
+		return valueParamRequiredError
 	}
 
 	var field = valueOf.Field(index)
@@ -796,25 +928,22 @@ func GreatThanField(typeOf reflect.Type, valueOf reflect.Value, index int, param
 		if field.Int() > anotherField.Int() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		if field.Uint() > anotherField.Uint() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	case reflect.Float32, reflect.Float64:
 		if field.Float() > anotherField.Float() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 
 	return errors.New("unsupported type")
 }
 func GreatEqualField(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -831,25 +960,22 @@ func GreatEqualField(typeOf reflect.Type, valueOf reflect.Value, index int, para
 		if field.Int() >= anotherField.Int() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		if field.Uint() >= anotherField.Uint() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	case reflect.Float32, reflect.Float64:
 		if field.Float() >= anotherField.Float() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return errors.New("unsupported type")
 }
 
 func LowerThanField(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -866,24 +992,21 @@ func LowerThanField(typeOf reflect.Type, valueOf reflect.Value, index int, param
 		if field.Int() < anotherField.Int() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		if field.Uint() < anotherField.Uint() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	case reflect.Float32, reflect.Float64:
 		if field.Float() < anotherField.Float() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return errors.New("unsupported type")
 }
 func LowerEqualField(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if param.Message == "" {
-		return messageParamRequiredError
-	}
 	if param.Value == "" {
 		return valueParamRequiredError
 	}
@@ -900,25 +1023,22 @@ func LowerEqualField(typeOf reflect.Type, valueOf reflect.Value, index int, para
 		if field.Int() <= anotherField.Int() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		if field.Uint() <= anotherField.Uint() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	case reflect.Float32, reflect.Float64:
 		if field.Float() <= anotherField.Float() {
 			return nil
 		}
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 	return errors.New("unsupported type")
 }
 
 func Round(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
-	if len(param.Message) == 0 {
-		return messageParamRequiredError
-	}
 	if len(param.Value) == 0 {
 		return valueParamRequiredError
 	}
@@ -941,46 +1061,257 @@ func Round(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) e
 
 	var s = strings.Split(text, ".")
 	if len(s) == 2 && len(s[1]) > v {
-		return errors.New(param.Message)
+		return param.Fail()
 	}
 
 	return nil
 }
 
-func init() {
-	_ = RegisterValidator("required", Required)
-	_ = RegisterValidator("max_length", MaxLength)
-	_ = RegisterValidator("min_length", MinLength)
-	_ = RegisterValidator("gt", GreaterThan)
-	_ = RegisterValidator("lt", LowerThan)
-	_ = RegisterValidator("ge", GreaterEqual)
-	_ = RegisterValidator("le", LowerEqual)
-	_ = RegisterValidator("equal", Equal)
-	_ = RegisterValidator("eq", Equal)
-	_ = RegisterValidator("equal_field", EqualField)
-	_ = RegisterValidator("ef", EqualField)
-	_ = RegisterValidator("method", Method)
-	_ = RegisterValidator("regexp", Regexp)
-	_ = RegisterValidator("email", Email)
-	_ = RegisterValidator("uuid", UUID)
-	_ = RegisterValidator("phone", Phone)
-	_ = RegisterValidator("between", Between)
-	_ = RegisterValidator("not_between", NotBetween)
-	_ = RegisterValidator("not_equal", NotEqual)
-	_ = RegisterValidator("ne", NotEqual)
-	_ = RegisterValidator("not_equal_field", NotEqualField)
-	_ = RegisterValidator("nef", NotEqualField)
-	_ = RegisterValidator("url", Url)
-	_ = RegisterValidator("contains", Contains)
-	_ = RegisterValidator("not_contains", NotContains)
-	_ = RegisterValidator("date_format", DateFormat)
-	_ = RegisterValidator("great_than_field", GreatThanField)
-	_ = RegisterValidator("gtf", GreatThanField)
-	_ = RegisterValidator("lower_than_field", LowerThanField)
-	_ = RegisterValidator("ltf", LowerThanField)
-	_ = RegisterValidator("great_equal_field", GreatEqualField)
-	_ = RegisterValidator("gef", GreatEqualField)
-	_ = RegisterValidator("lower_equal_field", LowerEqualField)
-	_ = RegisterValidator("lef", LowerEqualField)
-	_ = RegisterValidator("round", Round)
+// fieldCondition 条件规则中引用的其他字段, 例: Status=active
+type fieldCondition struct {
+	field string
+	value string
+}
+
+// parseConditions 解析空格分隔的条件, 例: v=Status=active Type=vip
+func parseConditions(value string, withValue bool) ([]fieldCondition, error) {
+	var conditions = make([]fieldCondition, 0)
+	for _, token := range strings.Fields(value) {
+		var field, val, ok = strings.Cut(token, "=")
+		if withValue != ok {
+			return nil, fmt.Errorf("%s syntax error", token)
+		}
+
+		conditions = append(conditions, fieldCondition{field: field, value: val})
+	}
+
+	if len(conditions) == 0 {
+		return nil, valueParamRequiredError
+	}
+
+	return conditions, nil
+}
+
+// lookupField 获取同一结构体中的其他字段, 指针字段会解引用
+func lookupField(valueOf reflect.Value, name string) (reflect.Value, error) {
+	var field = valueOf.FieldByName(name)
+	if !field.IsValid() {
+		return field, fmt.Errorf("no field named %s", name)
+	}
+
+	return field, nil
+}
+
+// fieldEqual 字段的值是否等于条件中的值, nil 指针只等于空字符串
+func fieldEqual(field reflect.Value, value string) bool {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return len(value) == 0
+		}
+
+		field = field.Elem()
+	}
+
+	return fmt.Sprint(field.Interface()) == value
+}
+
+// matchAll 所有条件是否都成立
+func matchAll(valueOf reflect.Value, param Param) (bool, error) {
+	var conditions, err = parseConditions(param.Value, true)
+	if err != nil {
+		return false, err
+	}
+
+	for _, cond := range conditions {
+		var field, err = lookupField(valueOf, cond.field)
+		if err != nil {
+			return false, err
+		}
+
+		if !fieldEqual(field, cond.value) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// countPresent 引用的字段中不为空的数量
+func countPresent(valueOf reflect.Value, param Param) (int, int, error) {
+	var conditions, err = parseConditions(param.Value, false)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var present int
+	for _, cond := range conditions {
+		var field, err = lookupField(valueOf, cond.field)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if isValid(field) {
+			present++
+		}
+	}
+
+	return present, len(conditions), nil
+}
+
+// RequiredIf 其他字段都等于指定值时必填, 例: required_if(m=请填写公司名称,v=Type=company)
+func RequiredIf(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var matched, err = matchAll(valueOf, param)
+	if err != nil {
+		return err
+	}
+
+	if matched && !isValid(valueOf.Field(index)) {
+		return param.Fail()
+	}
+
+	return nil
+}
+
+// RequiredUnless 除非其他字段都等于指定值, 否则必填
+func RequiredUnless(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var matched, err = matchAll(valueOf, param)
+	if err != nil {
+		return err
+	}
+
+	if !matched && !isValid(valueOf.Field(index)) {
+		return param.Fail()
+	}
+
+	return nil
+}
+
+// RequiredWith 任意一个其他字段不为空时必填, 例: required_with(m=请填写区号,v=Phone Mobile)
+func RequiredWith(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var present, _, err = countPresent(valueOf, param)
+	if err != nil {
+		return err
+	}
+
+	if present > 0 && !isValid(valueOf.Field(index)) {
+		return param.Fail()
+	}
+
+	return nil
+}
+
+// RequiredWithout 任意一个其他字段为空时必填
+func RequiredWithout(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var present, total, err = countPresent(valueOf, param)
+	if err != nil {
+		return err
+	}
+
+	if present < total && !isValid(valueOf.Field(index)) {
+		return param.Fail()
+	}
+
+	return nil
+}
+
+// ExcludedIf 其他字段都等于指定值时必须为空
+func ExcludedIf(typeOf reflect.Type, valueOf reflect.Value, index int, param Param) error {
+	var matched, err = matchAll(valueOf, param)
+	if err != nil {
+		return err
+	}
+
+	if matched && isValid(valueOf.Field(index)) {
+		return param.Fail()
+	}
+
+	return nil
+}
+
+// builtinValidators 内置的验证器, 每个 ValidatorLibrary 都会复制一份
+func builtinValidators() map[string]ValidatorFunc {
+	return map[string]ValidatorFunc{
+		"required":          Required,
+		"max_length":        MaxLength,
+		"min_length":        MinLength,
+		"gt":                GreaterThan,
+		"lt":                LowerThan,
+		"ge":                GreaterEqual,
+		"le":                LowerEqual,
+		"equal":             Equal,
+		"eq":                Equal,
+		"equal_field":       EqualField,
+		"ef":                EqualField,
+		"method":            Method,
+		"regexp":            Regexp,
+		"email":             Email,
+		"uuid":              UUID,
+		"phone":             Phone,
+		"between":           Between,
+		"not_between":       NotBetween,
+		"not_equal":         NotEqual,
+		"ne":                NotEqual,
+		"not_equal_field":   NotEqualField,
+		"nef":               NotEqualField,
+		"url":               Url,
+		"contains":          Contains,
+		"not_contains":      NotContains,
+		"date_format":       DateFormat,
+		"great_than_field":  GreatThanField,
+		"gtf":               GreatThanField,
+		"lower_than_field":  LowerThanField,
+		"ltf":               LowerThanField,
+		"great_equal_field": GreatEqualField,
+		"gef":               GreatEqualField,
+		"lower_equal_field": LowerEqualField,
+		"lef":               LowerEqualField,
+		"round":             Round,
+		"required_if":       RequiredIf,
+		"required_unless":   RequiredUnless,
+		"required_with":     RequiredWith,
+		"required_without":  RequiredWithout,
+		"excluded_if":       ExcludedIf,
+		"len":               Length,
+		"ip":                stringRule("ip", isIP),
+		"ipv4":              stringRule("ipv4", isIPv4),
+		"ipv6":              stringRule("ipv6", isIPv6),
+		"cidr":              stringRule("cidr", isCIDR),
+		"hostname":          stringRule("hostname", isHostname),
+		"fqdn":              stringRule("fqdn", isFQDN),
+		"mac":               stringRule("mac", isMAC),
+		"port":              numberRange("port", 1, 65535, true),
+		"json":              JSON,
+		"base64":            stringRule("base64", isBase64),
+		"base64url":         stringRule("base64url", isBase64URL),
+		"hex_color":         stringRule("hex_color", hexColorRegexp.MatchString),
+		"semver":            stringRule("semver", semverRegexp.MatchString),
+		"credit_card":       stringRule("credit_card", isCreditCard),
+		"isbn":              stringRule("isbn", isISBN),
+		"isbn10":            stringRule("isbn10", isISBN10),
+		"isbn13":            stringRule("isbn13", isISBN13),
+		"id_card":           stringRule("id_card", isIDCard),
+		"bank_card":         stringRule("bank_card", isBankCard),
+		"latitude":          numberRange("latitude", -90, 90, false),
+		"longitude":         numberRange("longitude", -180, 180, false),
+		"oneof":             OneOf,
+		"unique":            Unique,
+		"alpha":             stringRule("alpha", isAlpha),
+		"alphanumeric":      stringRule("alphanumeric", isAlphanumeric),
+		"ascii":             stringRule("ascii", isASCII),
+		"printable":         stringRule("printable", isPrintable),
+		"before":            Before,
+		"after":             After,
+		"min_age":           MinAge,
+		"max_age":           MaxAge,
+		"max_size":          MaxSize,
+		"min_size":          MinSize,
+		"mimes":             Mimes,
+		"ext":               Ext,
+		"max_width":         imageDimension(func(config image.Config, except int) bool { return config.Width <= except }),
+		"max_height":        imageDimension(func(config image.Config, except int) bool { return config.Height <= except }),
+		"min_width":         imageDimension(func(config image.Config, except int) bool { return config.Width >= except }),
+		"min_height":        imageDimension(func(config image.Config, except int) bool { return config.Height >= except }),
+		"max_files":         fileCount(func(count, except int) bool { return count <= except }),
+		"min_files":         fileCount(func(count, except int) bool { return count >= except }),
+	}
 }
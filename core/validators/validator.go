@@ -1,23 +1,43 @@
 package validators
 
-import "regexp"
-
-// 必须包含validate 或 params字段
-var validateParamRegexp = regexp.MustCompile(`(?P<validator>\w+)\((?P<params>.*)\)`)
+import "context"
 
 type Param struct {
 	Message string //用户自定义错误提示
 	Value   string
+	Params  map[string]string //其他参数, 例: layout, tz
 }
 
+// Validate 验证所有字段, 返回 ValidationErrors
 func Validate(value any) error {
 	return validatorLibrary.Validate(value)
 }
 
-type Default struct{}
+// ValidateFailFast 遇到第一个错误就返回
+func ValidateFailFast(value any) error {
+	return validatorLibrary.ValidateFailFast(value)
+}
+
+// ValidateContext 验证所有字段, ctx 会传给需要上下文的验证器和结构体
+func ValidateContext(ctx context.Context, value any) error {
+	return validatorLibrary.ValidateContext(ctx, value)
+}
+
+type Default struct {
+	FailFast bool //遇到第一个错误就返回, 默认收集所有字段的错误
+}
 
 func (d Default) Validate(validate any) error {
+	if d.FailFast {
+		return ValidateFailFast(validate)
+	}
+
 	return Validate(validate)
 }
 
+func (d Default) ValidateContext(ctx context.Context, validate any) error {
+	return validatorLibrary.validate(ctx, validate, d.FailFast)
+}
+
 var _ Validator = Default{}
+var _ ContextValidator = Default{}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"gin-core/core"
+	"gin-core/core/bind"
+	"gin-core/core/validators"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type benchUser struct {
+	Name   string   `form:"name" validate:"max_length(m=姓名长度不能大于10,v=10)"`
+	Email  string   `form:"email" validate:"email(m=邮箱格式错误)"`
+	Age    int      `form:"age" validate:"between(m=年龄不合法,v=1,120)"`
+	Score  float64  `form:"score" validate:"round(m=最多两位小数,v=2)"`
+	Code   string   `form:"code" validate:"regexp(m=编码格式错误,v=^[A-Z]{3}\\d+$)"`
+	Tags   []string `form:"tags"`
+	Page   int      `form:"page,1"`
+	Ignore string   `form:"-"`
+}
+
+var benchForm = url.Values{
+	"name":  {"gin"},
+	"email": {"gin@example.com"},
+	"age":   {"18"},
+	"score": {"99.5"},
+	"code":  {"ABC123"},
+	"tags":  {"a", "b", "c"},
+}
+
+func BenchmarkBindForm(b *testing.B) {
+	var binder = bind.URLValueBinder{TagName: "form", BindTagName: "bind", Source: bind.SourceForm}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var user benchUser
+		if err := binder.BindForm(benchForm, &user); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindMultipartForm(b *testing.B) {
+	var binder = bind.HttpMultipartFormBinder{
+		URLValueBinder: bind.URLValueBinder{TagName: "form", BindTagName: "bind", Source: bind.SourceForm},
+		FieldTag:       "file",
+	}
+	var form = &multipart.Form{Value: benchForm}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var user benchUser
+		if err := binder.BindMultipartForm(form, &user); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	var user = benchUser{Name: "gin", Email: "gin@example.com", Age: 18, Score: 99.5, Code: "ABC123"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := validators.Validate(&user); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkData(b *testing.B) {
+	var router = core.New()
+	router.GET("/user", func(c *core.Context) {
+		var user benchUser
+		if err := c.Data(&user); err != nil {
+			b.Fatal(err)
+		}
+	})
+	_ = router.TestInit()
+	runRequest(b, router, "GET", "/user?"+benchForm.Encode())
+}
+
+func TestBodyCache(t *testing.T) {
+	var router = core.New()
+	router.POST("/body", func(c *core.Context) {
+		var first, err = c.Body()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		//第二次读取使用缓存, 内容不变
+		second, err := c.Body()
+		if err != nil || string(second) != string(first) {
+			t.Errorf("Body() second read = %q, %v; want %q", second, err, first)
+		}
+
+		//读取过请求体之后仍然可以绑定
+		var user struct {
+			Name string `json:"name"`
+		}
+		if err = c.BindJSON(&user); err != nil || user.Name != "gin" {
+			t.Errorf("BindJSON after Body() = %+v, %v", user, err)
+		}
+	})
+	_ = router.TestInit()
+
+	var r = httptest.NewRequest("POST", "/body", strings.NewReader(`{"name":"gin"}`))
+	r.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+}
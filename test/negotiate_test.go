@@ -0,0 +1,47 @@
+package test
+
+import (
+	"gin-core/core"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncodable(t *testing.T) {
+	var router = core.New()
+	router.GET("/map", func(c *core.Context) {
+		if err := c.Negotiate(map[string]any{"name": "gin"}); err != nil {
+			c.AbortWithProblem(err)
+		}
+	})
+	router.GET("/list", func(c *core.Context) {
+		if err := c.Negotiate([]string{"gin"}); err != nil {
+			c.AbortWithProblem(err)
+		}
+	})
+	_ = router.TestInit()
+
+	var cases = []struct {
+		path        string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"/map", "application/x-protobuf", 406, "application/problem+json"},
+		{"/map", "application/x-protobuf, application/json;q=0.5", 200, "application/json"},
+		{"/map", "application/xml, application/yaml;q=0.9", 200, "application/yaml"},
+		{"/map", "application/toml", 200, "application/toml"},
+		{"/list", "application/toml", 406, "application/problem+json"},
+		{"/list", "application/toml, */*;q=0.1", 200, "application/json"},
+	}
+	for _, tc := range cases {
+		var r = httptest.NewRequest("GET", tc.path, nil)
+		r.Header.Set("Accept", tc.accept)
+		var w = httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		if w.Code != tc.status || !strings.HasPrefix(w.Header().Get("Content-Type"), tc.contentType) {
+			t.Errorf("%s %s = %d %q %s", tc.path, tc.accept, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+}
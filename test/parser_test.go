@@ -0,0 +1,104 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"gin-core/core"
+	"gin-core/core/color"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type parserUser struct {
+	Name string   `json:"name" xml:"name" yaml:"name" toml:"name" msgpack:"name" cbor:"name"`
+	Age  int      `json:"age" xml:"age" yaml:"age" toml:"age" msgpack:"age" cbor:"age"`
+	Tags []string `json:"tags" xml:"tags" yaml:"tags" toml:"tags" msgpack:"tags" cbor:"tags"`
+}
+
+func TestParsersRoundTrip(t *testing.T) {
+	var router = core.New()
+	router.POST("/bind", func(c *core.Context) {
+		if c.Query().Get("kind") == "proto" {
+			var value wrapperspb.StringValue
+			if err := c.Data(&value); err != nil {
+				c.AbortWithProblem(err)
+				return
+			}
+
+			_ = c.JSON(map[string]string{"value": value.GetValue()})
+			return
+		}
+
+		if c.Query().Get("kind") == "not_message" {
+			var user parserUser
+			var err = c.BindProtoBuf(&user)
+			if !errors.Is(err, color.ErrNotProtoMessage) {
+				t.Errorf("BindProtoBuf(non message) = %v", err)
+			}
+			return
+		}
+
+		var user parserUser
+		if err := c.Data(&user); err != nil {
+			c.AbortWithProblem(err)
+			return
+		}
+
+		_ = c.JSON(user)
+	})
+	_ = router.TestInit()
+
+	var user = parserUser{Name: "gin", Age: 18, Tags: []string{"a", "b"}}
+	var expect = "{\"name\":\"gin\",\"age\":18,\"tags\":[\"a\",\"b\"]}\n"
+	var formats = []struct {
+		contentType string
+		serializer  color.Serializer
+	}{
+		{"application/json", color.JsonSerializer{}},
+		{"application/xml", color.XmlSerializer{}},
+		{"application/yaml", color.YamlSerializer{}},
+		{"application/toml", color.TomlSerializer{}},
+		{"application/msgpack", color.MsgPackSerializer{}},
+		{"application/cbor", color.CborSerializer{}},
+		{"application/vnd.api+json", color.JsonSerializer{}},
+	}
+	for _, format := range formats {
+		var body bytes.Buffer
+		if err := format.serializer.Encode(&body, user); err != nil {
+			t.Fatalf("%s encode: %v", format.contentType, err)
+		}
+
+		var r = httptest.NewRequest("POST", "/bind", &body)
+		r.Header.Set("Content-Type", format.contentType)
+		var w = httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != 200 || w.Body.String() != expect {
+			t.Errorf("%s = %d %s", format.contentType, w.Code, w.Body.String())
+		}
+	}
+
+	var body bytes.Buffer
+	_ = color.ProtoBufSerializer{}.Encode(&body, wrapperspb.String("gin"))
+	var r = httptest.NewRequest("POST", "/bind?kind=proto", &body)
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	var w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.String() != "{\"value\":\"gin\"}\n" {
+		t.Errorf("protobuf = %d %s", w.Code, w.Body.String())
+	}
+
+	r = httptest.NewRequest("POST", "/bind?kind=not_message", bytes.NewReader(body.Bytes()))
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	//格式错误的请求体返回400
+	r = httptest.NewRequest("POST", "/bind", bytes.NewReader([]byte("name: [")))
+	r.Header.Set("Content-Type", "application/yaml")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != 400 {
+		t.Errorf("malformed yaml = %d %s", w.Code, w.Body.String())
+	}
+}
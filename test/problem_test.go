@@ -0,0 +1,96 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"gin-core/core"
+	"gin-core/core/bind"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type problemUser struct {
+	Name string `json:"name" xml:"name" form:"name" validate:"required"`
+	Age  int    `json:"age" xml:"age" form:"age"`
+}
+
+func TestProblemFromBindErrors(t *testing.T) {
+	var router = core.New()
+	router.SetStrict(&bind.Strict{DisallowUnknownFields: true, DisallowTrailingData: true, MaxBodySize: 64})
+	router.POST("/user", func(c *core.Context) {
+		var user problemUser
+		if err := c.Data(&user); err != nil {
+			c.AbortWithProblem(err)
+		}
+	})
+	_ = router.TestInit()
+
+	var cases = []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		field       string
+	}{
+		{"unknown field", "application/json", `{"name":"x","extra":1}`, 400, "extra"},
+		{"malformed json", "application/json", `{"name":`, 400, "body"},
+		{"syntax error", "application/json", `{"name" 1}`, 400, "body"},
+		{"type mismatch", "application/json", `{"name":1}`, 400, "name"},
+		{"trailing data", "application/json", `{"name":"x"} {}`, 400, "body"},
+		{"malformed xml", "application/xml", `<problemUser><name>x</problemUser>`, 400, "body"},
+		{"malformed form", "application/x-www-form-urlencoded", `name=%zz`, 400, "form"},
+		{"unknown form field", "application/x-www-form-urlencoded", `name=x&extra=1`, 400, "extra"},
+		{"invalid", "application/json", `{"age":1}`, 422, "name"},
+		{"too large", "application/json", `{"name":"` + strings.Repeat("x", 100) + `"}`, 413, ""},
+		{"unsupported", "text/csv", `name`, 415, ""},
+	}
+	for _, tc := range cases {
+		var r = httptest.NewRequest("POST", "/user", strings.NewReader(tc.body))
+		r.Header.Set("Content-Type", tc.contentType)
+		var w = httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		var problem core.ProblemDetails
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Errorf("%s: %v %q", tc.name, err, w.Body.String())
+			continue
+		}
+
+		if w.Code != tc.status || problem.Status != tc.status {
+			t.Errorf("%s: status = %d/%d, want %d: %s", tc.name, w.Code, problem.Status, tc.status, w.Body.String())
+		}
+
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/problem+json") {
+			t.Errorf("%s: Content-Type = %q", tc.name, w.Header().Get("Content-Type"))
+		}
+
+		if len(tc.field) > 0 && (len(problem.Errors) == 0 || problem.Errors[0].Field != tc.field) {
+			t.Errorf("%s: errors = %+v, want field %q", tc.name, problem.Errors, tc.field)
+		}
+	}
+}
+
+func TestProblemFromError(t *testing.T) {
+	var problem = core.ProblemFromError(errors.New("database password is hunter2"))
+	if problem.Status != 500 || len(problem.Detail) > 0 {
+		t.Errorf("unknown error = %+v", problem)
+	}
+
+	var custom = core.NewProblem(409, "already exists").With("id", 7)
+	if core.ProblemFromError(custom) != custom {
+		t.Error("ProblemDetails should be returned as is")
+	}
+
+	var data, _ = json.Marshal(custom)
+	var fields map[string]any
+	_ = json.Unmarshal(data, &fields)
+	if fields["id"] != float64(7) || fields["status"] != float64(409) || fields["title"] != "Conflict" {
+		t.Errorf("MarshalJSON = %s", data)
+	}
+
+	var decodeErr = json.Unmarshal([]byte(`{"name":true}`), &problemUser{})
+	if problem = core.ProblemFromError(decodeErr); problem.Status != 400 || len(problem.Errors) != 1 || problem.Errors[0].Field != "name" {
+		t.Errorf("decode error = %+v", problem)
+	}
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"errors"
+	"gin-core/core"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHTMLRenderError(t *testing.T) {
+	var router = core.New()
+	router.SetHTMLTemplates(core.NewHTMLTemplates(fstest.MapFS{
+		"index.html": {Data: []byte(`<h1>{{.}}</h1>`)},
+	}, "*.html"))
+	router.GET("/page", func(c *core.Context) {
+		if err := c.HTML(200, c.Query().Get("name"), "gin"); err != nil {
+			c.AbortWithProblem(core.NewProblem(500, err.Error()))
+		}
+	})
+	_ = router.TestInit()
+
+	var w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/page?name=index.html", nil))
+	if w.Code != 200 || w.Body.String() != "<h1>gin</h1>" {
+		t.Errorf("index.html = %d %q", w.Code, w.Body.String())
+	}
+
+	//模板渲染失败时仍然可以输出错误响应
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/page?name=missing.html", nil))
+	if w.Code != 500 || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/problem+json") ||
+		!strings.Contains(w.Body.String(), `"status":500`) {
+		t.Errorf("missing.html = %d %q %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
+
+func TestAttachmentFilename(t *testing.T) {
+	var file = filepath.Join(t.TempDir(), "report.csv")
+	_ = os.WriteFile(file, []byte("a,b"), 0644)
+
+	var router = core.New()
+	router.GET("/download", func(c *core.Context) {
+		_ = c.ServeFile(file, c.Query().Get("name"))
+	})
+	_ = router.TestInit()
+
+	var cases = map[string]string{
+		"report.csv":    `attachment; filename="report.csv"`,
+		`报表\a".csv`:     `attachment; filename="__\\a\".csv"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8%5Ca%22.csv`,
+		"a b;c.csv\x00": `attachment; filename="a b;c.csv_"; filename*=UTF-8''a%20b%3Bc.csv%00`,
+	}
+	for name, expect := range cases {
+		var w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/download?name="+url.QueryEscape(name), nil))
+		if got := w.Header().Get("Content-Disposition"); got != expect {
+			t.Errorf("%q: Content-Disposition = %s, want %s", name, got, expect)
+		}
+	}
+}
+
+func TestJSONRenders(t *testing.T) {
+	var data = map[string]any{"html": "<b>&</b>"}
+	var router = core.New()
+	router.GET("/render", func(c *core.Context) {
+		var err error
+		switch c.Query().Get("kind") {
+		case "jsonp":
+			if err = c.JSONP(data); err != nil && !errors.Is(err, core.ErrInvalidCallback) {
+				t.Errorf("JSONP error = %v", err)
+			}
+		case "secure":
+			err = c.SecureJSON([]int{1, 2})
+		case "pure":
+			err = c.PureJSON(data)
+		case "indented":
+			err = c.IndentedJSON(map[string]int{"a": 1})
+		case "stream":
+			var items = make(chan any, 3)
+			items <- 1
+			items <- "two"
+			items <- map[string]int{"three": 3}
+			close(items)
+			err = c.JSONStream(items)
+		}
+
+		if err != nil {
+			c.AbortWithProblem(core.NewProblem(400, err.Error()))
+		}
+	})
+	_ = router.TestInit()
+
+	var cases = []struct {
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/render?kind=jsonp&callback=app.done", 200, "application/javascript", "/**/app.done({\"html\":\"\\u003cb\\u003e\\u0026\\u003c/b\\u003e\"}\n);"},
+		{"/render?kind=jsonp", 200, "application/json", "{\"html\":\"\\u003cb\\u003e\\u0026\\u003c/b\\u003e\"}\n"},
+		{"/render?kind=jsonp&callback=" + url.QueryEscape("alert(1)//"), 400, "application/problem+json", ""},
+		{"/render?kind=jsonp&callback=" + strings.Repeat("a", 129), 400, "application/problem+json", ""},
+		{"/render?kind=secure", 200, "application/json", "while(1);[1,2]\n"},
+		{"/render?kind=pure", 200, "application/json", "{\"html\":\"<b>&</b>\"}\n"},
+		{"/render?kind=indented", 200, "application/json", "{\n    \"a\": 1\n}\n"},
+		{"/render?kind=stream", 200, "application/json", "[1\n,\"two\"\n,{\"three\":3}\n]"},
+	}
+	for _, tc := range cases {
+		var w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+		if w.Code != tc.status || !strings.HasPrefix(w.Header().Get("Content-Type"), tc.contentType) {
+			t.Errorf("%s = %d %q", tc.path, w.Code, w.Header().Get("Content-Type"))
+		}
+
+		if len(tc.body) > 0 && w.Body.String() != tc.body {
+			t.Errorf("%s body = %q, want %q", tc.path, w.Body.String(), tc.body)
+		}
+	}
+}
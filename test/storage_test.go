@@ -0,0 +1,313 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"gin-core/core"
+	"io/fs"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	var cases = map[string]string{
+		"avatar.png":             "avatar.png",
+		"../../etc/passwd":       "passwd",
+		`..\..\windows\win.ini`:  "win.ini",
+		"a<b>:c|d?.txt":          "a_b__c_d_.txt",
+		"..":                     "file",
+		" .hidden. ":             "hidden",
+		"":                       "file",
+		"报告\x00.pdf":             "报告_.pdf",
+		strings.Repeat("a", 300): strings.Repeat("a", 255),
+	}
+	for in, want := range cases {
+		if got := core.SanitizeFilename(in); got != want {
+			t.Errorf("SanitizeFilename(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	var long = core.SanitizeFilename(strings.Repeat("测", 100) + ".jpeg")
+	if len(long) > 255 || !strings.HasSuffix(long, ".jpeg") {
+		t.Errorf("long name not truncated: %d %q", len(long), long)
+	}
+}
+
+func TestLocalFileStorageSave(t *testing.T) {
+	var dir = t.TempDir()
+	var storage = &core.LocalFileStorage{MediaRoot: filepath.Join(dir, "media")}
+	var form = multipartForm(t, map[string][][]byte{
+		"file": {[]byte("one"), []byte("two"), []byte("one")},
+	}, map[string][]string{
+		"file": {"../../escape.txt", "../../escape.txt", "/abs/escape.txt"},
+	})
+	var files = form.File["file"]
+
+	var first, err = storage.Save(files[0])
+	if err != nil || first != "escape.txt" {
+		t.Fatalf("Save = %q, %v", first, err)
+	}
+
+	//同名文件不能覆盖
+	var second string
+	if second, err = storage.Save(files[1]); err != nil || second == first || !strings.HasPrefix(second, "escape-") {
+		t.Fatalf("Save = %q, %v", second, err)
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(dir, "media", first)); string(content) != "one" {
+		t.Errorf("content = %q", content)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, "escape.txt")); !os.IsNotExist(err) {
+		t.Errorf("file escaped MediaRoot: %v", err)
+	}
+
+	var names []core.FileInfo
+	if names, err = storage.List(""); err != nil || len(names) != 2 {
+		t.Errorf("List = %v, %v", names, err)
+	}
+
+	storage.Naming = core.HashName
+	var hashed string
+	if hashed, err = storage.Save(files[2]); err != nil || hashed != "7692c3ad3540bb803c020b3aee66cd8887123234ea0c6e7143c0add73ff431ed.txt" {
+		t.Errorf("HashName = %q, %v", hashed, err)
+	}
+
+	//内容相同时返回已有的文件, 不再保存副本
+	if again, err := storage.Save(files[0]); err != nil || again != hashed {
+		t.Errorf("HashName again = %q, %v", again, err)
+	}
+	if names, _ = storage.List(""); len(names) != 3 {
+		t.Errorf("List = %v", names)
+	}
+
+	//文件名伪造成已有文件的哈希, 内容不同时不能返回已有文件
+	storage.Naming = nil
+	var spoof = multipartForm(t, map[string][][]byte{"file": {[]byte("evil")}}, map[string][]string{"file": {hashed}})
+	if name, err := storage.Save(spoof.File["file"][0]); err != nil || name == hashed {
+		t.Errorf("spoofed name = %q, %v", name, err)
+	}
+
+	storage.Naming = core.DatePartitioned(core.UUIDName)
+	var uuid string
+	if uuid, err = storage.Save(files[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	var pattern = "^" + time.Now().Format("2006/01/02") + "/[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\\.txt$"
+	if !regexp.MustCompile(pattern).MatchString(uuid) {
+		t.Errorf("DatePartitioned(UUIDName) = %q", uuid)
+	}
+
+	if info, _ := os.Stat(filepath.Join(dir, "media", uuid)); info == nil || info.Mode().Perm() != 0644 {
+		t.Errorf("file mode = %v", info)
+	}
+
+	//链接失败且不是名称冲突时直接返回错误
+	storage.Naming = func(fileHeader *multipart.FileHeader) (string, error) {
+		return strings.Repeat("a", 300) + ".txt", nil
+	}
+	if name, err := storage.Save(files[0]); err == nil {
+		t.Errorf("name too long = %q", name)
+	}
+}
+
+func TestLocalFileStorageAccess(t *testing.T) {
+	var dir = t.TempDir()
+	var storage = &core.LocalFileStorage{MediaRoot: dir, BaseURL: "https://cdn.example.com/media"}
+	var form = multipartForm(t, map[string][][]byte{"file": {[]byte("hello")}}, map[string][]string{"file": {"报告 1.txt"}})
+
+	var name, err = storage.Save(form.File["file"][0])
+	if err != nil || name != "报告 1.txt" {
+		t.Fatalf("Save = %q, %v", name, err)
+	}
+
+	var file, err01 = storage.Open(name)
+	if err01 != nil {
+		t.Fatal(err01)
+	}
+	var content bytes.Buffer
+	_, _ = content.ReadFrom(file)
+	_ = file.Close()
+	if content.String() != "hello" {
+		t.Errorf("Open = %q", content.String())
+	}
+
+	var info core.FileInfo
+	if info, err = storage.Stat(name); err != nil || info.Name != name || info.Size != 5 || info.ModTime.IsZero() {
+		t.Errorf("Stat = %+v, %v", info, err)
+	}
+
+	//不存在的文件和目录都返回 fs.ErrNotExist
+	if err = os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, missing := range []string{"missing.txt", "sub", "../" + filepath.Base(dir)} {
+		if _, err = storage.Stat(missing); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%q) = %v", missing, err)
+		}
+	}
+	if _, err = storage.Open("missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(missing) = %v", err)
+	}
+
+	var urls = map[string]string{
+		name:                "https://cdn.example.com/media/%E6%8A%A5%E5%91%8A%201.txt",
+		"2024/01/a?b#c.txt": "https://cdn.example.com/media/2024/01/a%3Fb%23c.txt",
+	}
+	for in, want := range urls {
+		if got := storage.URL(in); got != want {
+			t.Errorf("URL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestContentStorage(t *testing.T) {
+	var dir = t.TempDir()
+	var storage = &core.ContentStorage{MediaRoot: dir, BaseURL: "/media/"}
+
+	var router = core.New()
+	router.POST("/upload", func(c *core.Context) {
+		var name, err = c.SaveUploadFileWith(storage, "file")
+		if err != nil {
+			t.Error(err)
+		}
+
+		_, _ = c.ResponseWriter.Write([]byte(name))
+	})
+	router.GET("/media", func(c *core.Context) {
+		if err := c.ServeStoredFileWith(storage, c.Query().Get("name")); err != nil {
+			c.AbortWithProblem(err)
+		}
+	})
+	_ = router.TestInit()
+
+	var upload = func(filename, content string) string {
+		var body bytes.Buffer
+		var writer = multipart.NewWriter(&body)
+		var part, _ = writer.CreateFormFile("file", filename)
+		_, _ = part.Write([]byte(content))
+		_ = writer.Close()
+
+		var r = httptest.NewRequest("POST", "/upload", &body)
+		r.Header.Set("Content-Type", writer.FormDataContentType())
+		var w = httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		return w.Body.String()
+	}
+
+	var first, second = upload("report.pdf", "same"), upload("report.pdf", "same")
+	if first != "report.pdf" || second == first {
+		t.Fatalf("names = %q, %q", first, second)
+	}
+
+	const hash = "0967115f2813a3541eaef77de9d9d5773f1c0c04314b0bbfe4ff3b3b1c55b5d5"
+	var blobs, _ = filepath.Glob(filepath.Join(dir, "blobs", "*", "*"))
+	if len(blobs) != 1 || filepath.Base(blobs[0]) != hash {
+		t.Fatalf("blobs = %v", blobs)
+	}
+
+	var link = storage.URL(first)
+	if link != "/media/"+hash+".pdf" || storage.URL(second) != link {
+		t.Fatalf("URL = %q, %q", link, storage.URL(second))
+	}
+
+	var w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/media?name="+path.Base(link), nil))
+	if w.Code != 200 || w.Body.String() != "same" {
+		t.Errorf("GET %s = %d %q", link, w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q", w.Header().Get("X-Content-Type-Options"))
+	}
+
+	//只能使用保存时的扩展名, 不能改成 .html 输出
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/media?name="+hash+".html", nil))
+	if w.Code != 404 {
+		t.Errorf("GET %s.html = %d %q", hash, w.Code, w.Header().Get("Content-Type"))
+	}
+
+	//引用计数为0时才删除内容
+	if err := storage.Delete(first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(blobs[0]); err != nil {
+		t.Fatalf("blob removed while referenced: %v", err)
+	}
+
+	//重新加载索引文件
+	var reloaded = &core.ContentStorage{MediaRoot: dir}
+	if files, err := reloaded.List(""); err != nil || len(files) != 1 || files[0].Name != second || files[0].Size != 4 {
+		t.Fatalf("List = %v, %v", files, err)
+	}
+
+	if err := reloaded.Delete(second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(blobs[0]); !os.IsNotExist(err) {
+		t.Errorf("blob not removed: %v", err)
+	}
+	if ok, err := reloaded.Exists(hash + ".pdf"); ok || err != nil {
+		t.Errorf("Exists = %v, %v", ok, err)
+	}
+	if err := reloaded.Delete(second); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Delete = %v", err)
+	}
+}
+
+func TestFileRefIndex(t *testing.T) {
+	var dir = t.TempDir()
+	var file = filepath.Join(dir, "index", "refs.json")
+	var index, err = core.NewFileRefIndex(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ref = core.ContentRef{Name: "a.txt", Hash: "aa", Size: 1}
+	if err = index.Store(ref); err != nil {
+		t.Fatal(err)
+	}
+	if err = index.Store(core.ContentRef{Name: "b.txt", Hash: "aa", Size: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = index.Delete("b.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	//重写后不能留下临时文件
+	var entries, _ = os.ReadDir(filepath.Dir(file))
+	if len(entries) != 1 || entries[0].Name() != "refs.json" {
+		t.Errorf("index dir = %v", entries)
+	}
+
+	var loaded *core.FileRefIndex
+	if loaded, err = core.NewFileRefIndex(file); err != nil {
+		t.Fatal(err)
+	}
+	if refs, _ := loaded.List(""); len(refs) != 1 || refs[0].Name != "a.txt" {
+		t.Errorf("reloaded = %v", refs)
+	}
+
+	//索引文件无法替换时返回错误, 内存中的修改也要回滚
+	if err = os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir(file, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = loaded.Store(core.ContentRef{Name: "c.txt", Hash: "cc"}); err == nil {
+		t.Error("Store expected error")
+	}
+	if _, ok, _ := loaded.Load("c.txt"); ok {
+		t.Error("failed Store was not rolled back")
+	}
+}
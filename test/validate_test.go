@@ -0,0 +1,498 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"gin-core/core"
+	"gin-core/core/bind"
+	"gin-core/core/validators"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validateValue 把值包装成只有一个字段的结构体, 使用指定的规则验证
+func validateValue(rule string, value any) error {
+	var typ = reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: reflect.TypeOf(value),
+		Tag:  reflect.StructTag(`validate:` + strconv.Quote(rule)),
+	}})
+	var v = reflect.New(typ)
+	v.Elem().Field(0).Set(reflect.ValueOf(value))
+
+	return validators.Validate(v.Interface())
+}
+
+func TestBuiltinValidators(t *testing.T) {
+	var cases = []struct {
+		rule  string
+		value any
+		valid bool
+	}{
+		{"ip", "192.168.1.1", true},
+		{"ip", "::1", true},
+		{"ip", "256.1.1.1", false},
+		{"ipv4", "10.0.0.1", true},
+		{"ipv4", "::ffff:10.0.0.1", false},
+		{"ipv6", "2001:db8::1", true},
+		{"ipv6", "10.0.0.1", false},
+		{"cidr", "10.0.0.0/8", true},
+		{"cidr", "10.0.0.0", false},
+		{"hostname", "api-01.example", true},
+		{"hostname", "-bad.example", false},
+		{"fqdn", "www.example.com", true},
+		{"fqdn", "localhost", false},
+		{"mac", "00:1A:2B:3C:4D:5E", true},
+		{"mac", "00:1A:2B:3C:4D", false},
+		{"port", 8080, true},
+		{"port", "443", true},
+		{"port", 0, false},
+		{"port", "70000", false},
+		{"json", `{"a":[1,2]}`, true},
+		{"json", []byte(`[1,`), false},
+		{"base64", "Z2luLWNvcmU=", true},
+		{"base64", "not base64!", false},
+		{"hex_color", "#1e90ff", true},
+		{"hex_color", "#fff", true},
+		{"hex_color", "1e90ff", false},
+		{"semver", "1.2.3-beta.1+build.5", true},
+		{"semver", "1.2", false},
+		{"credit_card", "4111 1111 1111 1111", true},
+		{"credit_card", "4111111111111112", false},
+		{"isbn", "0-306-40615-2", true},
+		{"isbn", "978-0-306-40615-7", true},
+		{"isbn", "978-0-306-40615-8", false},
+		{"isbn10", "080442957X", true},
+		{"isbn13", "0306406152", false},
+		{"id_card", "11010519491231002X", true},
+		{"id_card", "110105194912310021", false},
+		{"id_card", "110105194902300026", false},
+		{"bank_card", "6222600260001072444", true},
+		{"bank_card", "6222600260001072445", false},
+		{"latitude", 39.9, true},
+		{"latitude", "-91", false},
+		{"longitude", "116.39", true},
+		{"longitude", 181.0, false},
+		{"oneof(v=red green blue)", "green", true},
+		{"oneof(v=red green blue)", "pink", false},
+		{"oneof(v=1 2 3)", 2, true},
+		{"unique", []string{"a", "b"}, true},
+		{"unique", []int{1, 2, 1}, false},
+		{"unique", map[string]int{"a": 1, "b": 1}, false},
+		{"alpha", "Gin", true},
+		{"alpha", "Gin1", false},
+		{"alphanumeric", "Gin1", true},
+		{"alphanumeric", "Gin-1", false},
+		{"ascii", "gin core", true},
+		{"ascii", "框架", false},
+		{"printable", "gin core", true},
+		{"printable", "gin\x00", false},
+		{"len(v=2)", []int{1, 2}, true},
+		{"len(v=2)", map[string]int{"a": 1}, false},
+		{"len(v=2)", "框架", true},
+		{"max_length(v=3)", []string{"a", "b"}, true},
+		{"max_length(v=3)", []string{"a", "b", "c"}, false},
+		{"min_length(v=1)", map[string]int{"a": 1, "b": 2}, true},
+		{"min_length(v=1)", map[string]int{"a": 1}, false},
+		{"latitude", "NaN", false},
+		{"longitude", "+Inf", false},
+		{"unique", []struct{ V any }{{[]int{1}}, {[]int{2}}}, true},
+		{"unique", []struct{ V any }{{[]int{1}}, {[]int{1}}}, false},
+		{"oneof(v=red green)", (*string)(nil), true},
+		{"len(v=2)", (*string)(nil), true},
+		{"json", (*string)(nil), true},
+		{"ip", (*string)(nil), true},
+		{"latitude", (*float64)(nil), true},
+		{"unique", (*[]int)(nil), true},
+		{"required;oneof(v=red green)", (*string)(nil), false},
+	}
+
+	for _, c := range cases {
+		var err = validateValue(c.rule, c.value)
+		if c.valid && err != nil {
+			t.Errorf("%s(%v): expected valid, got %v", c.rule, c.value, err)
+		}
+
+		if !c.valid && (!validators.IsValidationError(err) || strings.Contains(err.Error(), "support")) {
+			t.Errorf("%s(%v): expected validation error, got %v", c.rule, c.value, err)
+		}
+	}
+}
+
+func TestValidateMessages(t *testing.T) {
+	var user = struct {
+		Age int `json:"age" validate:"ge(v=18)"`
+	}{Age: 16}
+
+	var err = validators.Validate(&user)
+	if err == nil || err.Error() != "age: age must be greater than or equal to 18" {
+		t.Errorf("en = %v", err)
+	}
+
+	err = validators.ValidateContext(validators.WithLocale(context.Background(), "zh-CN"), &user)
+	if err == nil || err.Error() != "age: age必须大于或等于18" {
+		t.Errorf("zh = %v", err)
+	}
+}
+
+type booking struct {
+	StartAt  time.Time  `validate:"after(v=now)"`
+	EndAt    *time.Time `validate:"after(v=StartAt);before(v=now+30d)"`
+	Day      string     `validate:"between(v=today,today+7d,layout=2006-01-02,tz=Asia/Shanghai)"`
+	Birthday string     `validate:"min_age(v=18,layout=2006-01-02);max_age(v=120,layout=2006-01-02)"`
+}
+
+func TestTimeValidators(t *testing.T) {
+	var loc, _ = time.LoadLocation("Asia/Shanghai")
+	var now = time.Now()
+	var end = now.Add(48 * time.Hour)
+	var valid = booking{
+		StartAt:  now.Add(time.Hour),
+		EndAt:    &end,
+		Day:      now.In(loc).AddDate(0, 0, 1).Format("2006-01-02"),
+		Birthday: now.AddDate(-20, 0, 0).Format("2006-01-02"),
+	}
+	if err := validators.Validate(&valid); err != nil {
+		t.Fatalf("expected valid booking, got %v", err)
+	}
+
+	var before = now.Add(-time.Hour)
+	var invalid = booking{
+		StartAt:  now.Add(-time.Hour),
+		EndAt:    &before,
+		Day:      now.In(loc).AddDate(0, 0, 10).Format("2006-01-02"),
+		Birthday: now.AddDate(-17, 0, 0).Format("2006-01-02"),
+	}
+	var errs validators.ValidationErrors
+	if !errors.As(validators.Validate(&invalid), &errs) || len(errs) != 4 {
+		t.Fatalf("expected 4 validation errors, got %v", errs)
+	}
+
+	var rules = []string{"after", "after", "between", "min_age"}
+	for i, err := range errs {
+		if err.Rule != rules[i] {
+			t.Errorf("field %s: expected rule %s, got %s", err.Path, rules[i], err.Rule)
+		}
+	}
+
+	//没有 layout 的日期字符串范围
+	for value, valid := range map[string]bool{
+		"2025-06-01":           true,
+		"2020-01-01":           true,
+		"2025-06-01T08:00:00Z": true,
+		"2019-12-31":           false,
+		"2031-01-01":           false,
+	} {
+		var err = validateValue("between(v=2020-01-01,2030-12-31)", value)
+		if valid != (err == nil) {
+			t.Errorf("between(v=2020-01-01,2030-12-31) %s: %v", value, err)
+		}
+	}
+}
+
+func TestValidateTagSyntax(t *testing.T) {
+	var cases = []struct {
+		rule  string
+		value any
+		valid bool
+	}{
+		{`regexp(m="格式错误, 例如 (ABC1)",v=^[A-Z]{3}\d+(,\d+)?$)`, "ABC1,2", true},
+		{`regexp(m="格式错误, 例如 (ABC1)",v=^[A-Z]{3}\d+(,\d+)?$)`, "ABC", false},
+		{`between(m=年龄不合法,v=1,120)`, 18, true},
+		{`oneof(v='a b',m=请选择\, 其中一个)`, "b", true},
+		{`required;max_length(v=3) min_length(v=1)`, "ab", true},
+	}
+	for _, c := range cases {
+		var err = validateValue(c.rule, c.value)
+		if c.valid != (err == nil) {
+			t.Errorf("%s(%v): expected valid=%v, got %v", c.rule, c.value, c.valid, err)
+		}
+	}
+
+	var err = validateValue(`oneof(v='a b',m=请选择\, 其中一个)`, "c")
+	if err == nil || !strings.Contains(err.Error(), "请选择, 其中一个") {
+		t.Errorf("expected escaped message, got %v", err)
+	}
+
+	for _, rule := range []string{`regexp(v=(abc`, `missing_rule`, `required(m="x" y)`, `regexp(v="a)`} {
+		var tagErr *validators.TagError
+		if err = validateValue(rule, ""); !errors.As(err, &tagErr) {
+			t.Errorf("%s: expected TagError, got %v", rule, err)
+		}
+	}
+}
+
+func TestValidateTopLevel(t *testing.T) {
+	var items = []schemaItem{{Sku: "ABC", Qty: 2}, {Qty: 2}}
+	var err = validators.Validate(&items)
+	if err == nil || !strings.HasPrefix(err.Error(), "[1].sku: ") {
+		t.Errorf("slice = %v", err)
+	}
+
+	if err = validators.Validate(map[string]*schemaItem{"a": {Sku: "A", Qty: 100}, "b": nil}); err == nil || !strings.HasPrefix(err.Error(), "[a].qty: ") {
+		t.Errorf("map = %v", err)
+	}
+
+	if err = validators.Validate([]string{"a"}); err != nil {
+		t.Errorf("[]string = %v", err)
+	}
+
+	var nilItem *schemaItem
+	for _, value := range []any{nil, nilItem, &nilItem} {
+		if err = validators.Validate(value); err == nil || validators.IsValidationError(err) {
+			t.Errorf("Validate(%#v) = %v", value, err)
+		}
+	}
+
+	var router = core.New()
+	router.POST("/items", func(c *core.Context) {
+		var items []schemaItem
+		if err := c.Data(&items); err != nil {
+			c.AbortWithProblem(err)
+		}
+	})
+	_ = router.TestInit()
+
+	var r = httptest.NewRequest("POST", "/items", strings.NewReader(`[{"sku":"ABC","qty":1},{"sku":"abc","qty":1}]`))
+	r.Header.Set("Content-Type", "application/json")
+	var w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != 422 || !strings.Contains(w.Body.String(), `"field":"[1].sku"`) {
+		t.Errorf("POST /items = %d %s", w.Code, w.Body.String())
+	}
+}
+
+type HookPage struct {
+	Size int `json:"size"`
+}
+
+func (p HookPage) Validate() error {
+	if p.Size > 100 {
+		return errors.New("size too large")
+	}
+
+	return nil
+}
+
+type hookQuery struct {
+	HookPage
+	Keyword string `json:"keyword"`
+}
+
+type hookOrder struct {
+	HookPage
+	Status string `json:"status"`
+}
+
+func (o *hookOrder) ValidateContext(ctx context.Context) error {
+	if o.Status == "x" {
+		return errors.New("invalid status")
+	}
+
+	return nil
+}
+
+func TestValidateEmbeddedHooks(t *testing.T) {
+	//提升到外层的 Validate 只调用一次
+	if err := validators.Validate(&hookQuery{HookPage: HookPage{Size: 500}}); err == nil || err.Error() != "size too large" {
+		t.Errorf("promoted hook = %v", err)
+	}
+
+	//外层调用的是 ValidateContext, 匿名字段的 Validate 仍然需要调用
+	var err = validators.Validate(&hookOrder{HookPage: HookPage{Size: 500}, Status: "x"})
+	if err == nil || err.Error() != "size too large; invalid status" {
+		t.Errorf("embedded hook = %v", err)
+	}
+}
+
+type schemaItem struct {
+	Sku string `json:"sku" validate:"required;regexp(v=^[A-Z]+$)"`
+	Qty int    `json:"qty" validate:"between(v=1,99)"`
+}
+
+type schemaOrder struct {
+	Email string       `json:"email" label:"邮箱" validate:"required;email"`
+	Color string       `json:"color" validate:"oneof(v=red green)"`
+	Items []schemaItem `json:"items" validate:"dive"`
+	Code  string       `json:"code" validate:"even"`
+	Pin   string       `json:"pin" validate:"eq(v=123)"`
+	Count int          `json:"count" validate:"eq(v=3)"`
+}
+
+func TestSchema(t *testing.T) {
+	var library = validators.NewLibrary()
+	_ = library.Register("even", func(typeOf reflect.Type, valueOf reflect.Value, index int, param validators.Param) error {
+		return nil
+	})
+	library.RegisterSchema("even", func(schema map[string]any, param validators.Param) {
+		schema["pattern"] = "^[0-9]*[02468]$"
+	})
+
+	var schema, err = library.Schema(&schemaOrder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []any{1, nil, []schemaItem{}} {
+		if _, err = validators.Schema(value); err == nil {
+			t.Errorf("Schema(%#v) expected error", value)
+		}
+	}
+
+	var data, _ = json.Marshal(schema)
+	for _, expect := range []string{
+		`"$schema":"https://json-schema.org/draft/2020-12/schema"`,
+		`"required":["email"]`,
+		`"email":{"format":"email","title":"邮箱","type":"string"}`,
+		`"color":{"enum":["red","green"],"type":"string"}`,
+		`"items":{"items":{"$ref":"#/$defs/schemaItem"},"type":"array"}`,
+		`"qty":{"maximum":99,"minimum":1,"type":"integer"}`,
+		`"sku":{"pattern":"^[A-Z]+$","type":"string"}`,
+		`"code":{"pattern":"^[0-9]*[02468]$","type":"string"}`,
+		`"pin":{"const":"123","type":"string"}`,
+		`"count":{"const":3,"type":"integer"}`,
+	} {
+		if !strings.Contains(string(data), expect) {
+			t.Errorf("schema %s does not contain %s", data, expect)
+		}
+	}
+}
+
+type uploadForm struct {
+	Avatar *multipart.FileHeader   `file:"avatar" validate:"required;max_size(v=1KB);mimes(v=image/*);ext(v=png);max_width(v=4)"`
+	Docs   []*multipart.FileHeader `file:"docs" validate:"max_files(v=1);dive;mimes(v=text/plain)"`
+}
+
+// multipartForm 构造包含文件的表单
+func multipartForm(t *testing.T, files map[string][][]byte, names map[string][]string) *multipart.Form {
+	var body bytes.Buffer
+	var writer = multipart.NewWriter(&body)
+	for key, contents := range files {
+		for i, content := range contents {
+			var part, err = writer.CreateFormFile(key, names[key][i])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _ = part.Write(content)
+		}
+	}
+	_ = writer.Close()
+
+	var form, err = multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return form
+}
+
+func TestFileValidators(t *testing.T) {
+	var img = image.NewRGBA(image.Rect(0, 0, 2, 2))
+	var png8 bytes.Buffer
+	_ = png.Encode(&png8, img)
+
+	var binder = bind.HttpMultipartFormBinder{
+		URLValueBinder: bind.URLValueBinder{TagName: "form", BindTagName: "bind", Source: bind.SourceForm},
+		FieldTag:       "file",
+	}
+
+	var valid = multipartForm(t,
+		map[string][][]byte{"avatar": {png8.Bytes()}, "docs": {[]byte("hello")}},
+		map[string][]string{"avatar": {"a.png"}, "docs": {"a.txt"}})
+	var form uploadForm
+	if err := binder.BindMultipartForm(valid, &form); err != nil {
+		t.Fatal(err)
+	}
+
+	if form.Avatar == nil || len(form.Docs) != 1 {
+		t.Fatalf("files are not bound by the file tag: %+v", form)
+	}
+
+	if err := validators.Validate(&form); err != nil {
+		t.Fatalf("expected valid upload, got %v", err)
+	}
+
+	var invalid = multipartForm(t,
+		map[string][][]byte{"avatar": {[]byte("plain text pretending to be png")}, "docs": {png8.Bytes(), []byte("b")}},
+		map[string][]string{"avatar": {"a.png"}, "docs": {"a.txt", "b.txt"}})
+	form = uploadForm{}
+	if err := binder.BindMultipartForm(invalid, &form); err != nil {
+		t.Fatal(err)
+	}
+
+	var errs validators.ValidationErrors
+	if !errors.As(validators.Validate(&form), &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 validation errors, got %v", errs)
+	}
+
+	if errs[0].Rule != "mimes" || errs[1].Rule != "max_files" {
+		t.Errorf("unexpected rules: %v", errs)
+	}
+}
+
+type libraryUser struct {
+	Name string `validate:"required;upper"`
+}
+
+func TestLibrary(t *testing.T) {
+	var upper = func(typeOf reflect.Type, valueOf reflect.Value, index int, param validators.Param) error {
+		var value = valueOf.Field(index).String()
+		if value != strings.ToUpper(value) {
+			return errors.New("must be upper")
+		}
+		return nil
+	}
+
+	//NewLibrary 包含内置规则, 但没有自定义规则
+	var library = validators.NewLibrary()
+	if err := library.Validate(&struct {
+		Name string `validate:"required"`
+	}{}); err == nil {
+		t.Error("NewLibrary: required expected error")
+	}
+	if err := library.Validate(&libraryUser{Name: "GIN"}); err == nil {
+		t.Error("NewLibrary: unknown rule expected error")
+	}
+
+	//副本注册的规则不影响默认的管理器
+	var clone = validators.DefaultLibrary().Clone()
+	if err := clone.Register("upper", upper); err != nil {
+		t.Fatal(err)
+	}
+	if err := clone.Validate(&libraryUser{Name: "GIN"}); err != nil {
+		t.Errorf("Clone: %v", err)
+	}
+	if err := clone.Validate(&libraryUser{Name: "gin"}); err == nil {
+		t.Error("Clone: lower case expected error")
+	}
+	if err := validators.Validate(&libraryUser{Name: "GIN"}); err == nil {
+		t.Error("Clone: rule leaked into the default library")
+	}
+
+	//覆盖内置规则, 已经缓存的验证计划需要失效
+	if err := clone.Validate(&libraryUser{}); err == nil {
+		t.Error("Override: required expected error before override")
+	}
+	clone.Override("required", func(typeOf reflect.Type, valueOf reflect.Value, index int, param validators.Param) error {
+		return nil
+	})
+	if err := clone.Validate(&libraryUser{}); err != nil {
+		t.Errorf("Override: %v", err)
+	}
+
+	//删除规则后, 已经缓存的验证计划不能继续使用
+	clone.Unregister("upper")
+	if err := clone.Validate(&libraryUser{Name: "GIN"}); err == nil {
+		t.Error("Unregister: cached plan still used")
+	}
+}